@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"sync"
+	"sync/atomic"
 )
 
 type OmadaClient struct {
@@ -16,10 +18,12 @@ type OmadaClient struct {
 
 	httpClient     *http.Client
 	omadaCId       string
-	baseUrl        string
+	endpoints      *endpointSet
 	clientId       string
 	clientSecret   string
 	accessTokenCtx *accessTokenCtx
+	cache          atomic.Pointer[responseCache]
+	breaker        atomic.Pointer[circuitBreaker]
 }
 
 type tokenState int64
@@ -34,6 +38,8 @@ const defaultPageSize = 100
 type accessTokenCtx struct {
 	token      string
 	tokenState tokenState
+	// Incremented each time a new token is acquired so a stale token can't reset a newer one
+	generation uint64
 	//ttl 		int
 	mu *sync.Mutex
 }
@@ -42,11 +48,16 @@ func NewClient(baseUrl, omadaCId, clientId, clientSecret string, disableCertVeri
 	client := &http.Client{Transport: &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: disableCertVerification},
 	}}
+	return NewClientWithHttpClient(baseUrl, omadaCId, clientId, clientSecret, client)
+}
 
+// NewClientWithHttpClient is like NewClient but uses the given http.Client, for callers that need control over
+// TLS, proxies or timeouts
+func NewClientWithHttpClient(baseUrl, omadaCId, clientId, clientSecret string, httpClient *http.Client) *OmadaClient {
 	c := OmadaClient{
-		httpClient:   client,
+		httpClient:   httpClient,
 		omadaCId:     omadaCId,
-		baseUrl:      baseUrl,
+		endpoints:    newEndpointSet(baseUrl),
 		clientSecret: clientSecret,
 		clientId:     clientId,
 		accessTokenCtx: &accessTokenCtx{
@@ -57,11 +68,55 @@ func NewClient(baseUrl, omadaCId, clientId, clientSecret string, disableCertVeri
 	return &c
 }
 
+type UnexpectedStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected response error: %d %s", e.StatusCode, e.Status)
+}
+
 type EnvelopeResponse struct {
 	ErrorCode int    `json:"errorCode"`
 	Message   string `json:"msg"`
 }
 
+const (
+	errorCodeOperationForbidden = -1005
+	errorCodeTokenExpired       = -44112
+)
+
+var (
+	// ErrClientNotFound is returned when a client doesn't exist: by BlockClient, UnblockClient and ReconnectClient when
+	// the site has no record of the MAC, and by lookups such as LocateClient when no client matches
+	ErrClientNotFound   = errors.New("client not found")
+	ErrPermissionDenied = errors.New("operation not permitted for this application")
+)
+
+// ApiError is a non-zero errorCode in a response envelope. It matches ErrPermissionDenied with errors.Is when the
+// controller rejects the operation for this application.
+type ApiError struct {
+	ErrorCode int
+	Message   string
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("error response: %d: %s", e.ErrorCode, e.Message)
+}
+
+func (e *ApiError) Is(target error) bool {
+	return target == ErrPermissionDenied && e.ErrorCode == errorCodeOperationForbidden
+}
+
+// Err returns the envelope's error as an *ApiError, or nil if the request succeeded
+func (e EnvelopeResponse) Err() error {
+	if e.ErrorCode == 0 {
+		return nil
+	}
+	return &ApiError{ErrorCode: e.ErrorCode, Message: e.Message}
+}
+
 type AccessTokenResponse struct {
 	EnvelopeResponse
 	Result struct {
@@ -72,8 +127,22 @@ type AccessTokenResponse struct {
 	} `json:"result"`
 }
 
+// GetToken fetches a new access token. It counts towards the circuit breaker like any other request.
 func (c *OmadaClient) GetToken() (*AccessTokenResponse, error) {
-	path := fmt.Sprintf("%s/openapi/authorize/token?grant_type=client_credentials&client_id=%s&client_secret=%s", c.baseUrl, c.clientId, c.clientSecret)
+	breaker := c.breaker.Load()
+	if breaker == nil {
+		return c.getToken()
+	}
+	if err := breaker.allow(); err != nil {
+		return nil, err
+	}
+	token, err := c.getToken()
+	breaker.record(err)
+	return token, err
+}
+
+func (c *OmadaClient) getToken() (*AccessTokenResponse, error) {
+	path := fmt.Sprintf("%s/openapi/authorize/token?grant_type=client_credentials&client_id=%s&client_secret=%s", c.ActiveBaseUrl(), c.clientId, c.clientSecret)
 	payload := map[string]string{
 		"omadacId": c.omadaCId,
 	}
@@ -85,14 +154,14 @@ func (c *OmadaClient) GetToken() (*AccessTokenResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer response.Body.Close()
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("response status %d: %s", response.StatusCode, response.Status)
+		return nil, &UnexpectedStatusError{StatusCode: response.StatusCode, Status: response.Status}
 	}
 	bodyBytes, err := io.ReadAll(response.Body)
 	if err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
 
 	tokenResponse := &AccessTokenResponse{}
 	err = json.Unmarshal(bodyBytes, tokenResponse)
@@ -103,82 +172,101 @@ func (c *OmadaClient) GetToken() (*AccessTokenResponse, error) {
 }
 
 func (c *OmadaClient) httpDoWrapped(request *http.Request, mapToJsonStructType interface{}) error {
-	return c.internalHttpDoWithAuthContextAndJsonMarshalling(request, mapToJsonStructType, 1)
+	allBytes, err := c.internalHttpDo(request)
+	if err != nil {
+		return err
+	}
+
+	// Finally, map to JSON
+	err = json.Unmarshal(allBytes, mapToJsonStructType)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
-func (c *OmadaClient) internalHttpDoWithAuthContextAndJsonMarshalling(request *http.Request, mapToJsonStructType interface{}, tries int) error {
+func (c *OmadaClient) internalHttpDoWithAuthContext(request *http.Request, tries int) ([]byte, error) {
 	if tries > 2 {
-		return fmt.Errorf("could not perform request after refreshing token")
+		return nil, fmt.Errorf("could not perform request after refreshing token")
 	}
-	err := c.accessTokenCtx.initialiseAccessTokenIfNeeded(c)
+	token, generation, err := c.accessTokenCtx.initialiseAccessTokenIfNeeded(c)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	request.Header.Set("Authorization", fmt.Sprintf("AccessToken=%s", c.accessTokenCtx.getAccessToken()))
+	request.Header.Set("Authorization", fmt.Sprintf("AccessToken=%s", token))
+	// The request may be a retry, so make sure any body is sent again from the start
+	if request.GetBody != nil {
+		request.Body, err = request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
 	response, err := c.httpClient.Do(request)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer response.Body.Close()
 	// Should be a 200, even for errors
 	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected response error: %d %s", response.StatusCode, response.Status)
+		return nil, &UnexpectedStatusError{StatusCode: response.StatusCode, Status: response.Status}
 	}
 
 	// We need to check the body. An expired token still returns a 200, but the error is in the payload :(
 	allBytes, err := io.ReadAll(response.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check the response envelope for the expired token
 	envelope := &EnvelopeResponse{}
 	err = json.Unmarshal(allBytes, envelope)
 	if err != nil {
-		return err
-	}
-	if envelope.ErrorCode == -44112 {
-		// Token expired, refresh the token and try again
-		c.accessTokenCtx.resetAccessToken()
-		return c.internalHttpDoWithAuthContextAndJsonMarshalling(request, mapToJsonStructType, tries+1)
+		return nil, err
 	}
-
-	// Finally, map to JSON
-	err = json.Unmarshal(allBytes, mapToJsonStructType)
-	if err != nil {
-		return err
+	if envelope.ErrorCode == errorCodeTokenExpired {
+		// Token expired, refresh the token and try again. Only the token this request was sent with is reset, if
+		// another request has already refreshed it we just pick up the new one.
+		c.accessTokenCtx.resetAccessToken(generation)
+		return c.internalHttpDoWithAuthContext(request, tries+1)
 	}
-	return nil
-
+	return allBytes, nil
 }
 
-func (a *accessTokenCtx) getAccessToken() string {
+func (a *accessTokenCtx) resetAccessToken(generation uint64) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	return a.token
+	if a.generation != generation {
+		return
+	}
+	a.tokenState = TokenStateUninitialised
+	a.token = ""
 }
 
-func (a *accessTokenCtx) resetAccessToken() {
+func (a *accessTokenCtx) clearAccessToken() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.tokenState = TokenStateUninitialised
 	a.token = ""
 }
 
-func (a *accessTokenCtx) initialiseAccessTokenIfNeeded(c *OmadaClient) error {
+// initialiseAccessTokenIfNeeded returns the current token and its generation, fetching a new one if required. The
+// lock is held for the duration of the fetch so concurrent callers wait for, and then share, a single refresh.
+func (a *accessTokenCtx) initialiseAccessTokenIfNeeded(c *OmadaClient) (string, uint64, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if a.tokenState == TokenStateUninitialised {
-		token, err := c.GetToken()
+		// Requests count the token fetch they trigger towards the breaker themselves
+		token, err := c.getToken()
 		if err != nil {
-			return err
+			return "", 0, err
 		}
 		if token.ErrorCode != 0 {
-			return fmt.Errorf("token error response: %d: %s", token.ErrorCode, token.Message)
+			return "", 0, fmt.Errorf("token error response: %d: %s", token.ErrorCode, token.Message)
 		}
 		a.tokenState = TokenStateActive
 		a.token = token.Result.AccessToken
+		a.generation++
 	}
-	return nil
+	return a.token, a.generation, nil
 }
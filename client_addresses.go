@@ -0,0 +1,145 @@
+package omada
+
+import (
+	"net/netip"
+)
+
+type IPv6Scope int
+
+const (
+	IPv6ScopeOther IPv6Scope = iota
+	IPv6ScopeLinkLocal
+	// fc00::/7, routable within a site but not on the internet
+	IPv6ScopeUniqueLocal
+	IPv6ScopeGlobal
+)
+
+func (s IPv6Scope) String() string {
+	switch s {
+	case IPv6ScopeLinkLocal:
+		return "link-local"
+	case IPv6ScopeUniqueLocal:
+		return "unique-local"
+	case IPv6ScopeGlobal:
+		return "global"
+	}
+	return "other"
+}
+
+var uniqueLocalPrefix = netip.MustParsePrefix("fc00::/7")
+
+func ClassifyIPv6(addr netip.Addr) IPv6Scope {
+	switch {
+	case !addr.Is6() || addr.Is4In6():
+		return IPv6ScopeOther
+	case addr.IsLinkLocalUnicast():
+		return IPv6ScopeLinkLocal
+	case uniqueLocalPrefix.Contains(addr):
+		return IPv6ScopeUniqueLocal
+	case addr.IsGlobalUnicast():
+		return IPv6ScopeGlobal
+	}
+	return IPv6ScopeOther
+}
+
+// Addr is the client's IPv4 address, false if the controller didn't report a valid one
+func (c ClientInfo) Addr() (netip.Addr, bool) {
+	return parseAddr(c.IP)
+}
+
+// FixedAddr is the DHCP reservation configured for the client, false if there isn't one
+func (c ClientInfo) FixedAddr() (netip.Addr, bool) {
+	if !c.IpSetting.UseFixedAddr {
+		return netip.Addr{}, false
+	}
+	return parseAddr(c.IpSetting.IP)
+}
+
+// IPv6Addrs returns the client's valid IPv6 addresses, skipping any that can't be parsed
+func (c ClientInfo) IPv6Addrs() []netip.Addr {
+	var addrs []netip.Addr
+	for _, raw := range c.IPv6List {
+		if addr, ok := parseAddr(raw); ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+func (c ClientInfo) IPv6AddrsWithScope(scope IPv6Scope) []netip.Addr {
+	var addrs []netip.Addr
+	for _, addr := range c.IPv6Addrs() {
+		if ClassifyIPv6(addr) == scope {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// Addrs returns every address the client has, IPv4 first
+func (c ClientInfo) Addrs() []netip.Addr {
+	var addrs []netip.Addr
+	if addr, ok := c.Addr(); ok {
+		addrs = append(addrs, addr)
+	}
+	return append(addrs, c.IPv6Addrs()...)
+}
+
+func FilterClients(clients []ClientInfo, keep func(client ClientInfo) bool) []ClientInfo {
+	var filtered []ClientInfo
+	for _, client := range clients {
+		if keep(client) {
+			filtered = append(filtered, client)
+		}
+	}
+	return filtered
+}
+
+// FilterClientsByPrefix keeps the clients with any address, IPv4 or IPv6, inside prefix
+func FilterClientsByPrefix(clients []ClientInfo, prefix netip.Prefix) []ClientInfo {
+	return FilterClients(clients, func(client ClientInfo) bool {
+		for _, addr := range client.Addrs() {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func FilterClientsByVLAN(clients []ClientInfo, vid int) []ClientInfo {
+	return FilterClients(clients, func(client ClientInfo) bool { return client.VID == vid })
+}
+
+func FilterClientsByNetworkName(clients []ClientInfo, networkName string) []ClientInfo {
+	return FilterClients(clients, func(client ClientInfo) bool { return client.NetworkName == networkName })
+}
+
+// GroupClientsByIPv4Subnet groups clients by the /bits subnet their IPv4 address is in. Clients without a valid
+// IPv4 address are left out.
+func GroupClientsByIPv4Subnet(clients []ClientInfo, bits int) map[netip.Prefix][]ClientInfo {
+	groups := map[netip.Prefix][]ClientInfo{}
+	for _, client := range clients {
+		addr, ok := client.Addr()
+		if !ok || !addr.Is4() {
+			continue
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		groups[prefix] = append(groups[prefix], client)
+	}
+	return groups
+}
+
+func parseAddr(raw string) (netip.Addr, bool) {
+	if raw == "" {
+		return netip.Addr{}, false
+	}
+	addr, err := netip.ParseAddr(raw)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr, true
+}
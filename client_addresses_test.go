@@ -0,0 +1,82 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/netip"
+	"testing"
+)
+
+func testAddressClients() []ClientInfo {
+	phone := ClientInfo{Name: "phone", IP: "192.168.1.10", VID: 10, NetworkName: "LAN",
+		IPv6List: []string{"fe80::1", "2001:db8::10", "fd12:3456::10"}}
+	laptop := ClientInfo{Name: "laptop", IP: "192.168.2.20", VID: 20, NetworkName: "IoT"}
+	printer := ClientInfo{Name: "printer", IP: "not-an-ip", VID: 10, NetworkName: "LAN",
+		IPv6List: []string{"2001:db8:1::30", "garbage"}}
+	printer.IpSetting.UseFixedAddr = true
+	printer.IpSetting.IP = "192.168.1.30"
+	return []ClientInfo{phone, laptop, printer}
+}
+
+// testClient fills in the MAC, and the name if it's empty, on a fixture that only sets the fields a test cares about
+func testClient(mac string, client ClientInfo) ClientInfo {
+	client.MAC = MustParseMAC(mac)
+	if client.Name == "" {
+		client.Name = mac
+	}
+	return client
+}
+
+func clientNames(clients []ClientInfo) []string {
+	var names []string
+	for _, client := range clients {
+		names = append(names, client.Name)
+	}
+	return names
+}
+
+func TestClientInfo_AddressAccessors(t *testing.T) {
+	clients := testAddressClients()
+
+	addr, ok := clients[0].Addr()
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParseAddr("192.168.1.10"), addr)
+	_, ok = clients[2].Addr()
+	assert.False(t, ok)
+
+	_, ok = clients[0].FixedAddr()
+	assert.False(t, ok)
+	fixed, ok := clients[2].FixedAddr()
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParseAddr("192.168.1.30"), fixed)
+
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("2001:db8:1::30")}, clients[2].IPv6Addrs())
+	assert.Len(t, clients[0].Addrs(), 4)
+}
+
+func TestClientInfo_IPv6AddrsWithScope(t *testing.T) {
+	phone := testAddressClients()[0]
+
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("fe80::1")}, phone.IPv6AddrsWithScope(IPv6ScopeLinkLocal))
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("2001:db8::10")}, phone.IPv6AddrsWithScope(IPv6ScopeGlobal))
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("fd12:3456::10")}, phone.IPv6AddrsWithScope(IPv6ScopeUniqueLocal))
+	assert.Equal(t, IPv6ScopeOther, ClassifyIPv6(netip.MustParseAddr("10.0.0.1")))
+	assert.Equal(t, "link-local", IPv6ScopeLinkLocal.String())
+}
+
+func TestFilterClients_ByPrefixVLANAndNetworkName(t *testing.T) {
+	clients := testAddressClients()
+
+	assert.Equal(t, []string{"phone"}, clientNames(FilterClientsByPrefix(clients, netip.MustParsePrefix("192.168.1.0/24"))))
+	assert.Equal(t, []string{"phone", "printer"}, clientNames(FilterClientsByPrefix(clients, netip.MustParsePrefix("2001:db8::/32"))))
+	assert.Equal(t, []string{"phone", "printer"}, clientNames(FilterClientsByVLAN(clients, 10)))
+	assert.Equal(t, []string{"laptop"}, clientNames(FilterClientsByNetworkName(clients, "IoT")))
+	assert.Empty(t, FilterClientsByNetworkName(clients, "Guest"))
+}
+
+func TestGroupClientsByIPv4Subnet(t *testing.T) {
+	groups := GroupClientsByIPv4Subnet(testAddressClients(), 24)
+
+	assert.Len(t, groups, 2)
+	assert.Equal(t, []string{"phone"}, clientNames(groups[netip.MustParsePrefix("192.168.1.0/24")]))
+	assert.Equal(t, []string{"laptop"}, clientNames(groups[netip.MustParsePrefix("192.168.2.0/24")]))
+}
@@ -0,0 +1,171 @@
+package omada
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Band int
+
+const (
+	Band2g Band = iota
+	Band5g
+	Band6g
+)
+
+func (b Band) String() string {
+	switch b {
+	case Band2g:
+		return "2.4GHz"
+	case Band5g:
+		return "5GHz"
+	case Band6g:
+		return "6GHz"
+	}
+	return fmt.Sprintf("Band(%d)", int(b))
+}
+
+// Band folds the second 5GHz radio into Band5g
+func (r RadioId) Band() Band {
+	switch r {
+	case RadioId5g, RadioId5g2:
+		return Band5g
+	case RadioId6g:
+		return Band6g
+	}
+	return Band2g
+}
+
+type BandDistribution struct {
+	Total int
+	Num2g int
+	Num5g int
+	Num6g int
+}
+
+// Share is from 0 to 1
+func (d BandDistribution) Share(band Band) float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	switch band {
+	case Band2g:
+		return float64(d.Num2g) / float64(d.Total)
+	case Band5g:
+		return float64(d.Num5g) / float64(d.Total)
+	case Band6g:
+		return float64(d.Num6g) / float64(d.Total)
+	}
+	return 0
+}
+
+// BandDistribution covers the wireless clients counted in the stat
+func (s ClientStat) BandDistribution() BandDistribution {
+	return BandDistribution{Total: s.Num2g + s.Num5g + s.Num6g, Num2g: s.Num2g, Num5g: s.Num5g, Num6g: s.Num6g}
+}
+
+func BandDistributionBySSID(clients []ClientInfo) map[string]BandDistribution {
+	distributions := map[string]BandDistribution{}
+	for ssid, stat := range ClientStatsBySSID(clients) {
+		distributions[ssid] = stat.BandDistribution()
+	}
+	return distributions
+}
+
+func BandDistributionByAP(clients []ClientInfo) map[MAC]BandDistribution {
+	distributions := map[MAC]BandDistribution{}
+	for apMac, stat := range ClientStatsByAP(clients) {
+		distributions[apMac] = stat.BandDistribution()
+	}
+	return distributions
+}
+
+// BandHistory remembers which bands each wireless client has been seen on, so that clients that could be on 5 or
+// 6GHz but are sitting on 2.4GHz can be found. Feed it the client list each time it's polled.
+type BandHistory struct {
+	mu        sync.Mutex
+	seen      map[MAC]map[Band]time.Time
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewBandHistory forgets sightings older than retention, so passing clients and randomised MACs don't build up.
+// Defaults to a week.
+func NewBandHistory(retention time.Duration) *BandHistory {
+	if retention <= 0 {
+		retention = 7 * 24 * time.Hour
+	}
+	return &BandHistory{seen: map[MAC]map[Band]time.Time{}, retention: retention, now: time.Now}
+}
+
+func (h *BandHistory) Observe(clients []ClientInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	for mac, bands := range h.seen {
+		for band, at := range bands {
+			if now.Sub(at) > h.retention {
+				delete(bands, band)
+			}
+		}
+		if len(bands) == 0 {
+			delete(h.seen, mac)
+		}
+	}
+	for _, client := range clients {
+		if !client.Wireless {
+			continue
+		}
+		bands, ok := h.seen[client.MAC]
+		if !ok {
+			bands = map[Band]time.Time{}
+			h.seen[client.MAC] = bands
+		}
+		bands[client.RadioId.Band()] = now
+		for _, link := range client.MultiLink {
+			bands[link.RadioId.Band()] = now
+		}
+	}
+}
+
+type StuckClient struct {
+	Client ClientInfo
+	// The highest band the client has been seen on, Band2g if it has only been seen on 2.4GHz but reports
+	// Support5g2
+	HighestBand Band
+	// When the client was last on HighestBand, zero if it has never been seen there
+	LastSeenOnHighestBand time.Time
+}
+
+// StuckOn2g lists the clients currently on 2.4GHz that have been seen on 5 or 6GHz within the retention period, or
+// that report Support5g2. That's the only capability the client list reports, and it's only set for clients that
+// support an AP's second 5GHz radio, so a 5 or 6GHz capable client that has never been seen off 2.4GHz is missed.
+// Clients are in MAC order.
+func (h *BandHistory) StuckOn2g(clients []ClientInfo) []StuckClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	var stuck []StuckClient
+	for _, client := range clients {
+		if !client.Wireless || client.RadioId.Band() != Band2g || client.IsMLO() {
+			continue
+		}
+		candidate := StuckClient{Client: client, HighestBand: Band2g}
+		for band, at := range h.seen[client.MAC] {
+			if band > candidate.HighestBand && now.Sub(at) <= h.retention {
+				candidate.HighestBand, candidate.LastSeenOnHighestBand = band, at
+			}
+		}
+		if candidate.HighestBand == Band2g && !client.Support5g2 {
+			continue
+		}
+		stuck = append(stuck, candidate)
+	}
+	sort.Slice(stuck, func(i, j int) bool {
+		return bytes.Compare(stuck[i].Client.MAC[:], stuck[j].Client.MAC[:]) < 0
+	})
+	return stuck
+}
@@ -0,0 +1,85 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadioId_Band(t *testing.T) {
+	assert.Equal(t, Band2g, RadioId2g.Band())
+	assert.Equal(t, Band5g, RadioId5g.Band())
+	assert.Equal(t, Band5g, RadioId5g2.Band())
+	assert.Equal(t, Band6g, RadioId6g.Band())
+	assert.Equal(t, "6GHz", Band6g.String())
+	assert.Equal(t, "Band(7)", Band(7).String())
+}
+
+func TestBandDistribution_BySSIDAndAP(t *testing.T) {
+	clients := []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SSID: "Home", RadioId: RadioId2g}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SSID: "Home", RadioId: RadioId5g}),
+		testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), SSID: "Home", RadioId: RadioId5g2}),
+		testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), SSID: "IoT", RadioId: RadioId2g}),
+		testClient("55:55:55:55:55:55", ClientInfo{}),
+	}
+
+	bySSID := BandDistributionBySSID(clients)
+	assert.Equal(t, BandDistribution{Total: 3, Num2g: 1, Num5g: 2}, bySSID["Home"])
+	assert.Equal(t, BandDistribution{Total: 1, Num2g: 1}, bySSID["IoT"])
+	assert.InDelta(t, 2.0/3, bySSID["Home"].Share(Band5g), 0.001)
+	assert.Equal(t, 0.0, bySSID["Home"].Share(Band6g))
+	assert.Equal(t, 0.0, BandDistribution{}.Share(Band2g))
+
+	byAP := BandDistributionByAP(clients)
+	assert.Equal(t, BandDistribution{Total: 2, Num2g: 1, Num5g: 1}, byAP[MustParseMAC("aa:aa:aa:aa:aa:02")])
+}
+
+func TestBandHistory_FindsClientsStuckOn2g(t *testing.T) {
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	history := NewBandHistory(0)
+	history.now = func() time.Time { return now }
+
+	laptop := testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SSID: "Home", RadioId: RadioId6g})
+	phone := testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SSID: "Home", RadioId: RadioId5g})
+	plug := testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SSID: "IoT", RadioId: RadioId2g})
+	tablet := testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SSID: "Home", RadioId: RadioId2g, Support5g2: true})
+	history.Observe([]ClientInfo{laptop, phone, plug, tablet})
+	firstSeen := now
+
+	now = now.Add(time.Hour)
+	laptop.RadioId = RadioId2g
+	phone.RadioId = RadioId2g
+	history.Observe([]ClientInfo{laptop, phone, plug, tablet})
+
+	stuck := history.StuckOn2g([]ClientInfo{laptop, phone, plug, tablet})
+	assert.Equal(t, []StuckClient{
+		{Client: laptop, HighestBand: Band6g, LastSeenOnHighestBand: firstSeen},
+		{Client: phone, HighestBand: Band5g, LastSeenOnHighestBand: firstSeen},
+		{Client: tablet, HighestBand: Band2g},
+	}, stuck)
+
+	// Once back on a higher band it's no longer stuck
+	phone.RadioId = RadioId5g
+	assert.Len(t, history.StuckOn2g([]ClientInfo{laptop, phone, plug, tablet}), 2)
+}
+
+func TestBandHistory_ForgetsOldSightings(t *testing.T) {
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	history := NewBandHistory(24 * time.Hour)
+	history.now = func() time.Time { return now }
+
+	laptop := testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, RadioId: RadioId5g})
+	passing := testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, RadioId: RadioId5g})
+	history.Observe([]ClientInfo{laptop, passing})
+
+	now = now.Add(23 * time.Hour)
+	laptop.RadioId = RadioId2g
+	assert.Len(t, history.StuckOn2g([]ClientInfo{laptop}), 1)
+
+	now = now.Add(2 * time.Hour)
+	assert.Empty(t, history.StuckOn2g([]ClientInfo{laptop}))
+	history.Observe([]ClientInfo{laptop})
+	assert.Len(t, history.seen, 1)
+	assert.Equal(t, map[Band]time.Time{Band2g: now}, history.seen[laptop.MAC])
+}
@@ -0,0 +1,165 @@
+package omada
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CacheEndpoint identifies a cacheable endpoint for TTL configuration and invalidation
+type CacheEndpoint string
+
+const (
+	CacheEndpointSiteList          CacheEndpoint = "siteList"
+	CacheEndpointSiteInfo          CacheEndpoint = "siteInfo"
+	CacheEndpointScenarioList      CacheEndpoint = "scenarioList"
+	CacheEndpointSiteDeviceAccount CacheEndpoint = "siteDeviceAccount"
+	CacheEndpointRoleList          CacheEndpoint = "roleList"
+	CacheEndpointRoleInfo          CacheEndpoint = "roleInfo"
+	CacheEndpointClientList        CacheEndpoint = "clientList"
+	CacheEndpointClientInfo        CacheEndpoint = "clientInfo"
+	CacheEndpointKnownClientList   CacheEndpoint = "knownClientList"
+)
+
+type responseCache struct {
+	mu         sync.Mutex
+	ttls       map[CacheEndpoint]time.Duration
+	entries    map[string]*cacheEntry
+	inflight   map[string]*inflightCall
+	generation uint64
+	now        func() time.Time
+}
+
+type cacheEntry struct {
+	endpoint CacheEndpoint
+	body     []byte
+	expires  time.Time
+}
+
+type inflightCall struct {
+	wg   sync.WaitGroup
+	body []byte
+	err  error
+}
+
+// EnableCache turns on response caching for the endpoints given a positive TTL. Endpoints not present in ttls
+// are never cached. Calling EnableCache again replaces the configuration and drops any cached responses.
+func (c *OmadaClient) EnableCache(ttls map[CacheEndpoint]time.Duration) {
+	copied := make(map[CacheEndpoint]time.Duration, len(ttls))
+	for endpoint, ttl := range ttls {
+		copied[endpoint] = ttl
+	}
+	c.cache.Store(&responseCache{
+		ttls:     copied,
+		entries:  map[string]*cacheEntry{},
+		inflight: map[string]*inflightCall{},
+		now:      time.Now,
+	})
+}
+
+func (c *OmadaClient) DisableCache() {
+	c.cache.Store(nil)
+}
+
+// InvalidateCache drops cached responses for the given endpoints, or every cached response if none are given
+func (c *OmadaClient) InvalidateCache(endpoints ...CacheEndpoint) {
+	if cache := c.cache.Load(); cache != nil {
+		cache.invalidate(endpoints...)
+	}
+}
+
+func (c *OmadaClient) httpDoCached(endpoint CacheEndpoint, request *http.Request, mapToJsonStructType interface{}) error {
+	cache := c.cache.Load()
+	if cache == nil || cache.ttl(endpoint) <= 0 || request.Method != http.MethodGet {
+		return c.httpDoWrapped(request, mapToJsonStructType)
+	}
+
+	allBytes, err := cache.getOrFetch(endpoint, request.URL.String(), func() ([]byte, error) {
+		return c.internalHttpDo(request)
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(allBytes, mapToJsonStructType)
+}
+
+// httpDoMutating performs a request that changes controller state and invalidates the related cached endpoints
+// once it has been sent, regardless of the outcome, since a failed call may still have been partially applied.
+func (c *OmadaClient) httpDoMutating(request *http.Request, mapToJsonStructType interface{}, invalidates ...CacheEndpoint) error {
+	err := c.httpDoWrapped(request, mapToJsonStructType)
+	if cache := c.cache.Load(); cache != nil && len(invalidates) > 0 {
+		cache.invalidate(invalidates...)
+	}
+	return err
+}
+
+func (r *responseCache) ttl(endpoint CacheEndpoint) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ttls[endpoint]
+}
+
+func (r *responseCache) getOrFetch(endpoint CacheEndpoint, key string, fetch func() ([]byte, error)) ([]byte, error) {
+	r.mu.Lock()
+	if entry, ok := r.entries[key]; ok {
+		if r.now().Before(entry.expires) {
+			r.mu.Unlock()
+			return entry.body, nil
+		}
+		delete(r.entries, key)
+	}
+	if call, ok := r.inflight[key]; ok {
+		// An identical request is already on the wire, wait for it rather than sending another
+		r.mu.Unlock()
+		call.wg.Wait()
+		return call.body, call.err
+	}
+	call := &inflightCall{}
+	call.wg.Add(1)
+	r.inflight[key] = call
+	generation := r.generation
+	r.mu.Unlock()
+
+	call.body, call.err = fetch()
+
+	r.mu.Lock()
+	delete(r.inflight, key)
+	// Don't store the response if the cache was invalidated while the request was in flight, it may be stale
+	if call.err == nil && generation == r.generation && isSuccessEnvelope(call.body) {
+		r.entries[key] = &cacheEntry{
+			endpoint: endpoint,
+			body:     call.body,
+			expires:  r.now().Add(r.ttls[endpoint]),
+		}
+	}
+	r.mu.Unlock()
+	call.wg.Done()
+	return call.body, call.err
+}
+
+func (r *responseCache) invalidate(endpoints ...CacheEndpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	if len(endpoints) == 0 {
+		r.entries = map[string]*cacheEntry{}
+		return
+	}
+	for key, entry := range r.entries {
+		for _, endpoint := range endpoints {
+			if entry.endpoint == endpoint {
+				delete(r.entries, key)
+				break
+			}
+		}
+	}
+}
+
+func isSuccessEnvelope(body []byte) bool {
+	envelope := &EnvelopeResponse{}
+	if err := json.Unmarshal(body, envelope); err != nil {
+		return false
+	}
+	return envelope.ErrorCode == 0
+}
@@ -0,0 +1,203 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func mockScenarioListServer(t *testing.T, calls *int32, release <-chan struct{}) *httptest.Server {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/scenarios", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if release != nil {
+			<-release
+		}
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": ["Hotel"]}`))
+		assert.NoError(t, err)
+	})
+	return httptest.NewServer(mockMux)
+}
+
+func TestOmadaClient_Cache_ServesRepeatedRequestsFromTheCache(t *testing.T) {
+	var calls int32
+	server := mockScenarioListServer(t, &calls, nil)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointScenarioList: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		scenarios, err := c.GetScenarioList()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Hotel"}, scenarios.Result)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOmadaClient_Cache_ExpiresEntriesAfterTheirTTL(t *testing.T) {
+	var calls int32
+	server := mockScenarioListServer(t, &calls, nil)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointScenarioList: time.Minute})
+	now := time.Now()
+	c.cache.Load().now = func() time.Time { return now }
+
+	_, err := c.GetScenarioList()
+	assert.NoError(t, err)
+	now = now.Add(59 * time.Second)
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	now = now.Add(2 * time.Second)
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOmadaClient_Cache_DoesNotCacheEndpointsWithoutATTL(t *testing.T) {
+	var calls int32
+	server := mockScenarioListServer(t, &calls, nil)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointSiteList: time.Minute})
+
+	_, err := c.GetScenarioList()
+	assert.NoError(t, err)
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOmadaClient_Cache_InvalidateDropsCachedEntries(t *testing.T) {
+	var calls int32
+	server := mockScenarioListServer(t, &calls, nil)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointScenarioList: time.Minute})
+
+	_, err := c.GetScenarioList()
+	assert.NoError(t, err)
+	c.InvalidateCache(CacheEndpointRoleList)
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	c.InvalidateCache(CacheEndpointScenarioList)
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	c.InvalidateCache()
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestOmadaClient_Cache_DoesNotCacheErrorResponses(t *testing.T) {
+	var calls int32
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/roles", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, err := w.Write([]byte(`{"errorCode": -1, "msg": "General error."}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointRoleList: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		roles, err := c.GetRoleList()
+		assert.NoError(t, err)
+		assert.Equal(t, -1, roles.ErrorCode)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOmadaClient_Cache_CoalescesConcurrentIdenticalRequests(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := mockScenarioListServer(t, &calls, release)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointScenarioList: time.Minute})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scenarios, err := c.GetScenarioList()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Hotel"}, scenarios.Result)
+		}()
+	}
+	// Give the goroutines a chance to pile up behind the first request before letting it complete
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOmadaClient_Cache_MutatingRequestsInvalidateRelatedEndpoints(t *testing.T) {
+	var calls int32
+	server := mockScenarioListServer(t, &calls, nil)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointScenarioList: time.Minute})
+
+	_, err := c.GetScenarioList()
+	assert.NoError(t, err)
+
+	request, err := http.NewRequest("POST", server.URL+"/openapi/v1/my-cid/scenarios", nil)
+	assert.NoError(t, err)
+	err = c.httpDoMutating(request, &EnvelopeResponse{}, CacheEndpointScenarioList)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestOmadaClient_Cache_CanBeToggledWhileRequestsAreInFlight(t *testing.T) {
+	var calls int32
+	server := mockScenarioListServer(t, &calls, nil)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, err := c.GetScenarioList()
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointScenarioList: time.Minute})
+		c.InvalidateCache()
+		c.SetFailoverBaseUrls(server.URL)
+		c.DisableCache()
+	}
+	wg.Wait()
+}
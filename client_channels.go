@@ -0,0 +1,279 @@
+package omada
+
+import (
+	"bytes"
+	"sort"
+)
+
+// APRadio is the channel one radio of an AP is using
+type APRadio struct {
+	APMac   MAC
+	APName  string
+	RadioId RadioId
+	Channel int
+	// Clients seen on the radio, zero for radios that came from device data
+	Clients int
+}
+
+type apRadioKey struct {
+	apMac   MAC
+	radioId RadioId
+}
+
+func (r APRadio) key() apRadioKey {
+	return apRadioKey{apMac: r.APMac, radioId: r.RadioId}
+}
+
+// APRadiosFromClients infers each AP radio's channel from the clients connected to it, taking the channel most of
+// its clients report. Links of MLO clients count towards their radios too.
+func APRadiosFromClients(clients []ClientInfo) []APRadio {
+	channelCounts := map[apRadioKey]map[int]int{}
+	radios := map[apRadioKey]*APRadio{}
+	observe := func(client ClientInfo, radioId RadioId, channel int) {
+		if channel <= 0 {
+			return
+		}
+		key := apRadioKey{apMac: client.APMac, radioId: radioId}
+		radio, ok := radios[key]
+		if !ok {
+			radio = &APRadio{APMac: client.APMac, APName: client.APName, RadioId: radioId}
+			radios[key] = radio
+			channelCounts[key] = map[int]int{}
+		}
+		radio.Clients++
+		channelCounts[key][channel]++
+	}
+	for _, client := range clients {
+		if !client.Wireless || client.APMac.IsZero() {
+			continue
+		}
+		if !client.IsMLO() {
+			observe(client, client.RadioId, client.Channel)
+			continue
+		}
+		for _, link := range client.MultiLink {
+			observe(client, link.RadioId, link.Channel)
+		}
+	}
+
+	result := make([]APRadio, 0, len(radios))
+	for key, radio := range radios {
+		best := 0
+		for channel, count := range channelCounts[key] {
+			if count > channelCounts[key][best] || (count == channelCounts[key][best] && channel < best) {
+				best = channel
+			}
+		}
+		radio.Channel = best
+		result = append(result, *radio)
+	}
+	sortAPRadios(result)
+	return result
+}
+
+func sortAPRadios(radios []APRadio) {
+	sort.Slice(radios, func(i, j int) bool {
+		if radios[i].APMac != radios[j].APMac {
+			return bytes.Compare(radios[i].APMac[:], radios[j].APMac[:]) < 0
+		}
+		return radios[i].RadioId < radios[j].RadioId
+	})
+}
+
+// Non-overlapping 20MHz channels, leaving out the 5GHz DFS range. 6GHz uses the preferred scanning channels.
+var defaultPlanChannels = map[Band][]int{
+	Band2g: {1, 6, 11},
+	Band5g: {36, 40, 44, 48, 149, 153, 157, 161, 165},
+	Band6g: {5, 21, 37, 53, 69, 85, 101, 117, 133, 149, 165, 181, 197, 213, 229},
+}
+
+type ChannelPlanConfig struct {
+	// Radios from device data. These take precedence over what APRadiosFromClients infers for the same AP radio,
+	// and cover radios that have no clients.
+	Radios []APRadio
+	// Pairs of APs close enough to interfere. When empty every AP is treated as a neighbour of every other, which
+	// suits a single small building. Radios on the same AP and band always count as neighbours.
+	Neighbours [][2]MAC
+	// Channels the plan may use per band, see defaultPlanChannels
+	Channels map[Band][]int
+}
+
+type ChannelConflict struct {
+	A APRadio
+	B APRadio
+}
+
+type ChannelAssignment struct {
+	APRadio
+	SuggestedChannel int
+}
+
+func (a ChannelAssignment) Changed() bool {
+	return a.Channel != a.SuggestedChannel
+}
+
+type ChannelReport struct {
+	Radios    []APRadio
+	Conflicts []ChannelConflict
+	Plan      []ChannelAssignment
+	// Conflicts left in the plan when there are more neighbouring radios than channels
+	PlanConflicts []ChannelConflict
+}
+
+// BuildChannelReport finds neighbouring AP radios whose channels overlap and suggests a plan that avoids it, keeping
+// radios on their current channel where that's already clear
+func BuildChannelReport(clients []ClientInfo, config ChannelPlanConfig) ChannelReport {
+	radios := mergeAPRadios(APRadiosFromClients(clients), config.Radios)
+	neighbours := neighbourFunc(config.Neighbours)
+	channels := config.Channels
+	if channels == nil {
+		channels = defaultPlanChannels
+	}
+
+	report := ChannelReport{Radios: radios, Conflicts: findChannelConflicts(radios, neighbours)}
+	planned := planChannels(radios, neighbours, channels)
+	plannedRadios := make([]APRadio, len(planned))
+	for i, assignment := range planned {
+		plannedRadios[i] = assignment.APRadio
+		plannedRadios[i].Channel = assignment.SuggestedChannel
+	}
+	report.Plan = planned
+	report.PlanConflicts = findChannelConflicts(plannedRadios, neighbours)
+	return report
+}
+
+func mergeAPRadios(inferred []APRadio, devices []APRadio) []APRadio {
+	merged := map[apRadioKey]APRadio{}
+	for _, radio := range inferred {
+		merged[radio.key()] = radio
+	}
+	for _, radio := range devices {
+		if existing, ok := merged[radio.key()]; ok {
+			radio.Clients = existing.Clients
+			if radio.APName == "" {
+				radio.APName = existing.APName
+			}
+		}
+		merged[radio.key()] = radio
+	}
+	radios := make([]APRadio, 0, len(merged))
+	for _, radio := range merged {
+		radios = append(radios, radio)
+	}
+	sortAPRadios(radios)
+	return radios
+}
+
+func neighbourFunc(pairs [][2]MAC) func(a, b MAC) bool {
+	if len(pairs) == 0 {
+		return func(a, b MAC) bool { return true }
+	}
+	neighbours := map[[2]MAC]bool{}
+	for _, pair := range pairs {
+		neighbours[pair] = true
+		neighbours[[2]MAC{pair[1], pair[0]}] = true
+	}
+	return func(a, b MAC) bool { return a == b || neighbours[[2]MAC{a, b}] }
+}
+
+func radiosInterfere(a, b APRadio, neighbours func(a, b MAC) bool) bool {
+	if a.key() == b.key() || a.RadioId.Band() != b.RadioId.Band() || !neighbours(a.APMac, b.APMac) {
+		return false
+	}
+	return channelsOverlap(a.RadioId.Band(), a.Channel, b.Channel)
+}
+
+// 2.4GHz channels are 5MHz apart but 20MHz wide, so only channels five or more apart are clear of each other.
+// Elsewhere only the same channel is treated as overlapping, as channel widths aren't known.
+func channelsOverlap(band Band, a, b int) bool {
+	if a <= 0 || b <= 0 {
+		return false
+	}
+	if band == Band2g {
+		difference := a - b
+		if difference < 0 {
+			difference = -difference
+		}
+		return difference < 5
+	}
+	return a == b
+}
+
+func findChannelConflicts(radios []APRadio, neighbours func(a, b MAC) bool) []ChannelConflict {
+	var conflicts []ChannelConflict
+	for i := range radios {
+		for j := i + 1; j < len(radios); j++ {
+			if radiosInterfere(radios[i], radios[j], neighbours) {
+				conflicts = append(conflicts, ChannelConflict{A: radios[i], B: radios[j]})
+			}
+		}
+	}
+	return conflicts
+}
+
+// planChannels colours the neighbour graph greedily, most constrained radios first
+func planChannels(radios []APRadio, neighbours func(a, b MAC) bool, channels map[Band][]int) []ChannelAssignment {
+	isNeighbour := func(a, b APRadio) bool {
+		return a.key() != b.key() && a.RadioId.Band() == b.RadioId.Band() && neighbours(a.APMac, b.APMac)
+	}
+	degree := make([]int, len(radios))
+	for i := range radios {
+		for j := range radios {
+			if isNeighbour(radios[i], radios[j]) {
+				degree[i]++
+			}
+		}
+	}
+	order := make([]int, len(radios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return degree[order[i]] > degree[order[j]] })
+
+	suggested := make([]int, len(radios))
+	for _, i := range order {
+		band := radios[i].RadioId.Band()
+		candidates := channels[band]
+		if len(candidates) == 0 {
+			suggested[i] = radios[i].Channel
+			continue
+		}
+		conflictsOn := func(channel int) int {
+			conflicts := 0
+			for j := range radios {
+				if suggested[j] != 0 && isNeighbour(radios[i], radios[j]) && channelsOverlap(band, channel, suggested[j]) {
+					conflicts++
+				}
+			}
+			return conflicts
+		}
+
+		best, bestConflicts := 0, -1
+		for _, channel := range candidates {
+			conflicts := conflictsOn(channel)
+			if bestConflicts == -1 || conflicts < bestConflicts {
+				best, bestConflicts = channel, conflicts
+			}
+		}
+		// Don't move a radio that is already on a clear channel
+		if radios[i].Channel > 0 && conflictsOn(radios[i].Channel) <= bestConflicts && containsChannel(candidates, radios[i].Channel) {
+			best = radios[i].Channel
+		}
+		suggested[i] = best
+	}
+
+	plan := make([]ChannelAssignment, len(radios))
+	for i, radio := range radios {
+		plan[i] = ChannelAssignment{APRadio: radio, SuggestedChannel: suggested[i]}
+	}
+	return plan
+}
+
+func containsChannel(channels []int, channel int) bool {
+	for _, candidate := range channels {
+		if candidate == channel {
+			return true
+		}
+	}
+	return false
+}
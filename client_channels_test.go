@@ -0,0 +1,88 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAPRadiosFromClients_TakesTheMostCommonChannel(t *testing.T) {
+	radios := APRadiosFromClients([]ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId5g, Channel: 36}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId5g, Channel: 44}),
+		testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId5g, Channel: 36}),
+		testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId2g}),
+		testClient("55:55:55:55:55:55", ClientInfo{
+			Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01",
+			MultiLink: []ClientLink{{RadioId: RadioId5g, Channel: 36}, {RadioId: RadioId6g, Channel: 37}},
+		}),
+		testClient("66:66:66:66:66:66", ClientInfo{Channel: 6}),
+	})
+
+	assert.Equal(t, []APRadio{
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId5g, Channel: 36, Clients: 4},
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId6g, Channel: 37, Clients: 1},
+	}, radios)
+}
+
+func TestChannelsOverlap(t *testing.T) {
+	assert.True(t, channelsOverlap(Band2g, 1, 3))
+	assert.True(t, channelsOverlap(Band2g, 6, 2))
+	assert.False(t, channelsOverlap(Band2g, 1, 6))
+	assert.True(t, channelsOverlap(Band5g, 36, 36))
+	assert.False(t, channelsOverlap(Band5g, 36, 40))
+	assert.False(t, channelsOverlap(Band5g, 0, 0))
+}
+
+func TestBuildChannelReport_ResolvesOverlapping2gChannels(t *testing.T) {
+	report := BuildChannelReport([]ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId2g, Channel: 1}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP 02", RadioId: RadioId2g, Channel: 3}),
+		testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:03"), APName: "AP 03", RadioId: RadioId2g, Channel: 6}),
+	}, ChannelPlanConfig{})
+
+	assert.Len(t, report.Radios, 3)
+	assert.Len(t, report.Conflicts, 2)
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:02"), report.Conflicts[0].B.APMac)
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:03"), report.Conflicts[1].B.APMac)
+
+	var suggested []int
+	for _, assignment := range report.Plan {
+		suggested = append(suggested, assignment.SuggestedChannel)
+	}
+	assert.Equal(t, []int{1, 6, 11}, suggested)
+	assert.False(t, report.Plan[0].Changed())
+	assert.True(t, report.Plan[1].Changed())
+	assert.Empty(t, report.PlanConflicts)
+}
+
+func TestBuildChannelReport_OnlyConsidersNeighbours(t *testing.T) {
+	report := BuildChannelReport([]ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId5g, Channel: 36}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP 02", RadioId: RadioId5g, Channel: 36}),
+		testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:03"), APName: "AP 03", RadioId: RadioId5g, Channel: 36}),
+	}, ChannelPlanConfig{
+		Neighbours: [][2]MAC{{MustParseMAC("aa:aa:aa:aa:aa:01"), MustParseMAC("aa:aa:aa:aa:aa:02")}},
+	})
+
+	assert.Len(t, report.Conflicts, 1)
+	assert.Equal(t, []int{36, 40, 36}, []int{report.Plan[0].SuggestedChannel, report.Plan[1].SuggestedChannel, report.Plan[2].SuggestedChannel})
+	assert.Empty(t, report.PlanConflicts)
+}
+
+func TestBuildChannelReport_UsesDeviceRadiosAndReportsUnresolvableConflicts(t *testing.T) {
+	report := BuildChannelReport([]ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId2g, Channel: 6}),
+	}, ChannelPlanConfig{
+		Radios: []APRadio{
+			{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId2g, Channel: 1},
+			{APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "Attic", RadioId: RadioId2g, Channel: 1},
+		},
+		Channels: map[Band][]int{Band2g: {1}},
+	})
+
+	// Device data wins over what the clients suggest, but keeps the client count and name
+	assert.Equal(t, APRadio{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", RadioId: RadioId2g, Channel: 1, Clients: 1}, report.Radios[0])
+	assert.Equal(t, "Attic", report.Radios[1].APName)
+	assert.Len(t, report.Conflicts, 1)
+	assert.Len(t, report.PlanConflicts, 1)
+}
@@ -0,0 +1,163 @@
+package omada
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+type CircuitBreakerConfig struct {
+	// Consecutive connection failures or 5xx responses before the circuit opens
+	FailureThreshold int
+	// How long the circuit stays open before letting a single probe request through
+	OpenDuration time.Duration
+	// Called on every state transition, from its own goroutine when the circuit half-opens
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitOpenError is returned without contacting the controller while the circuit is open
+type CircuitOpenError struct {
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open, controller unavailable until %s", e.RetryAt.Format(time.RFC3339))
+}
+
+type circuitBreaker struct {
+	mu            sync.Mutex
+	config        CircuitBreakerConfig
+	state         CircuitState
+	failures      int
+	retryAt       time.Time
+	probeInFlight bool
+	halfOpenTimer *time.Timer
+}
+
+// EnableCircuitBreaker makes the client fail fast with a *CircuitOpenError once the controller has been
+// unreachable for FailureThreshold consecutive requests
+func (c *OmadaClient) EnableCircuitBreaker(config CircuitBreakerConfig) {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = 30 * time.Second
+	}
+	c.breaker.Store(&circuitBreaker{config: config})
+}
+
+// CircuitState reports the current circuit breaker state, always CircuitClosed if no breaker is enabled
+func (c *OmadaClient) CircuitState() CircuitState {
+	breaker := c.breaker.Load()
+	if breaker == nil {
+		return CircuitClosed
+	}
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	return breaker.state
+}
+
+func (c *OmadaClient) internalHttpDo(request *http.Request) ([]byte, error) {
+	breaker := c.breaker.Load()
+	if breaker == nil {
+		return c.internalHttpDoWithFailover(request)
+	}
+	if err := breaker.allow(); err != nil {
+		return nil, err
+	}
+	allBytes, err := c.internalHttpDoWithFailover(request)
+	breaker.record(err)
+	return allBytes, err
+}
+
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		return &CircuitOpenError{RetryAt: b.retryAt}
+	case CircuitHalfOpen:
+		// Only one request gets to find out whether the controller is back
+		if b.probeInFlight {
+			return &CircuitOpenError{RetryAt: b.retryAt}
+		}
+		b.probeInFlight = true
+	}
+	return nil
+}
+
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	transition := [2]CircuitState{b.state, b.state}
+	wasProbe := b.state == CircuitHalfOpen
+	b.probeInFlight = false
+
+	// Only failures that indicate the controller is unreachable count, API level errors mean it's up
+	if err == nil || !shouldFailover(err) {
+		b.failures = 0
+		transition = b.setState(CircuitClosed)
+	} else {
+		b.failures++
+		if wasProbe || b.failures >= b.config.FailureThreshold {
+			transition = b.open()
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(transition[0], transition[1])
+}
+
+// open must be called with the lock held
+func (b *circuitBreaker) open() [2]CircuitState {
+	b.retryAt = time.Now().Add(b.config.OpenDuration)
+	if b.halfOpenTimer != nil {
+		b.halfOpenTimer.Stop()
+	}
+	b.halfOpenTimer = time.AfterFunc(b.config.OpenDuration, b.halfOpen)
+	return b.setState(CircuitOpen)
+}
+
+func (b *circuitBreaker) halfOpen() {
+	b.mu.Lock()
+	if b.state != CircuitOpen {
+		b.mu.Unlock()
+		return
+	}
+	transition := b.setState(CircuitHalfOpen)
+	b.mu.Unlock()
+	b.notify(transition[0], transition[1])
+}
+
+// setState must be called with the lock held
+func (b *circuitBreaker) setState(to CircuitState) [2]CircuitState {
+	from := b.state
+	b.state = to
+	return [2]CircuitState{from, to}
+}
+
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if b.config.OnStateChange != nil && from != to {
+		b.config.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,160 @@
+package omada
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func mockFlakyController(t *testing.T, down *int32, scenarioRequests *int32) *httptest.Server {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/scenarios", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(scenarioRequests, 1)
+		if atomic.LoadInt32(down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": ["Hotel"]}`))
+		assert.NoError(t, err)
+	})
+	return httptest.NewServer(mockMux)
+}
+
+type recordedTransitions struct {
+	mu          sync.Mutex
+	transitions []string
+}
+
+func (r *recordedTransitions) record(from, to CircuitState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, from.String()+"->"+to.String())
+}
+
+func (r *recordedTransitions) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.transitions...)
+}
+
+func TestOmadaClient_CircuitBreaker_OpensAfterConsecutiveFailuresAndFailsFast(t *testing.T) {
+	var down, scenarioRequests int32 = 1, 0
+	server := mockFlakyController(t, &down, &scenarioRequests)
+	defer server.Close()
+
+	transitions := &recordedTransitions{}
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenDuration: time.Hour, OnStateChange: transitions.record})
+
+	_, err := c.GetScenarioList()
+	assert.EqualError(t, err, "unexpected response error: 503 503 Service Unavailable")
+	assert.Equal(t, CircuitClosed, c.CircuitState())
+	_, err = c.GetScenarioList()
+	assert.Error(t, err)
+	assert.Equal(t, CircuitOpen, c.CircuitState())
+
+	_, err = c.GetScenarioList()
+	var openError *CircuitOpenError
+	assert.True(t, errors.As(err, &openError))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), openError.RetryAt, time.Minute)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&scenarioRequests))
+	assert.Equal(t, []string{"closed->open"}, transitions.get())
+}
+
+func TestOmadaClient_CircuitBreaker_HalfOpensAndClosesWhenTheProbeSucceeds(t *testing.T) {
+	var down, scenarioRequests int32 = 1, 0
+	server := mockFlakyController(t, &down, &scenarioRequests)
+	defer server.Close()
+
+	transitions := &recordedTransitions{}
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: 20 * time.Millisecond, OnStateChange: transitions.record})
+
+	_, err := c.GetScenarioList()
+	assert.Error(t, err)
+	assert.Equal(t, CircuitOpen, c.CircuitState())
+
+	assert.Eventually(t, func() bool { return c.CircuitState() == CircuitHalfOpen }, time.Second, 5*time.Millisecond)
+	atomic.StoreInt32(&down, 0)
+
+	scenarios, err := c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hotel"}, scenarios.Result)
+	assert.Equal(t, CircuitClosed, c.CircuitState())
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, transitions.get())
+}
+
+func TestOmadaClient_CircuitBreaker_ReopensWhenTheProbeFails(t *testing.T) {
+	var down, scenarioRequests int32 = 1, 0
+	server := mockFlakyController(t, &down, &scenarioRequests)
+	defer server.Close()
+
+	transitions := &recordedTransitions{}
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, OpenDuration: 20 * time.Millisecond, OnStateChange: transitions.record})
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetScenarioList()
+		assert.Error(t, err)
+	}
+	assert.Eventually(t, func() bool { return c.CircuitState() == CircuitHalfOpen }, time.Second, 5*time.Millisecond)
+
+	// A single failed probe is enough to open the circuit again
+	_, err := c.GetScenarioList()
+	assert.EqualError(t, err, "unexpected response error: 503 503 Service Unavailable")
+	assert.Equal(t, CircuitOpen, c.CircuitState())
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open"}, transitions.get())
+}
+
+func TestOmadaClient_CircuitBreaker_ApiErrorsDoNotCountAsFailures(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/scenarios", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Hour})
+
+	_, err := c.GetScenarioList()
+	assert.EqualError(t, err, "unexpected response error: 400 400 Bad Request")
+	assert.Equal(t, CircuitClosed, c.CircuitState())
+}
+
+func TestOmadaClient_CircuitBreaker_CountsTokenEndpointFailures(t *testing.T) {
+	var tokenRequests int32
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, OpenDuration: time.Hour})
+
+	_, err := c.GetScenarioList()
+	assert.EqualError(t, err, "unexpected response error: 503 503 Service Unavailable")
+	_, err = c.GetToken()
+	assert.Error(t, err)
+	assert.Equal(t, CircuitClosed, c.CircuitState())
+	_, err = c.GetScenarioList()
+	assert.Error(t, err)
+	assert.Equal(t, CircuitOpen, c.CircuitState())
+
+	var openError *CircuitOpenError
+	_, err = c.GetScenarioList()
+	assert.True(t, errors.As(err, &openError))
+	_, err = c.GetToken()
+	assert.True(t, errors.As(err, &openError))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&tokenRequests))
+}
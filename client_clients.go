@@ -1,16 +1,31 @@
 package omada
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 func (c *OmadaClient) GetClientList(siteId string, page int) (*GetClientListResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/clients?page=%d&pageSize=%d", c.baseUrl, c.omadaCId, siteId, page, c.PageSize)
+	return c.GetClientListWithQuery(siteId, page, ClientListQuery{})
+}
+
+// GetClientListWithQuery is GetClientList with the controller doing the searching, filtering and sorting
+func (c *OmadaClient) GetClientListWithQuery(siteId string, page int, query ClientListQuery) (*GetClientListResponse, error) {
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/clients?page=%d&pageSize=%d%s", c.ActiveBaseUrl(), c.omadaCId, siteId, page, c.PageSize, query.encode())
 	request, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	clientList := &GetClientListResponse{}
-	err = c.httpDoWrapped(request, clientList)
+	err = c.httpDoCached(CacheEndpointClientList, request, clientList)
 	if err != nil {
 		return nil, err
 	}
@@ -18,12 +33,33 @@ func (c *OmadaClient) GetClientList(siteId string, page int) (*GetClientListResp
 	return clientList, nil
 }
 
-func (c *OmadaClient) GetClientInfo(siteId string, clientMac string) (*GetClientInfoResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/clients/%s", c.baseUrl, c.omadaCId, siteId, clientMac)
+// GetAllClients follows GetClientListWithQuery through every page
+func (c *OmadaClient) GetAllClients(siteId string, query ClientListQuery) ([]ClientInfo, error) {
+	var clients []ClientInfo
+	for page := 1; ; page++ {
+		response, err := c.GetClientListWithQuery(siteId, page, query)
+		if err != nil {
+			return nil, err
+		}
+		if err := response.Err(); err != nil {
+			return nil, err
+		}
+		clients = append(clients, response.Result.Data...)
+		if len(response.Result.Data) == 0 || int64(len(clients)) >= response.Result.TotalRows {
+			return clients, nil
+		}
+	}
+}
+
+func (c *OmadaClient) GetClientInfo(siteId string, clientMac MAC) (*GetClientInfoResponse, error) {
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/clients/%s", c.ActiveBaseUrl(), c.omadaCId, siteId, clientMac)
 	request, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	clientInfo := &GetClientInfoResponse{}
-	err = c.httpDoWrapped(request, clientInfo)
+	err = c.httpDoCached(CacheEndpointClientInfo, request, clientInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +67,203 @@ func (c *OmadaClient) GetClientInfo(siteId string, clientMac string) (*GetClient
 	return clientInfo, nil
 }
 
+// BlockClient stops the client from connecting to the site's network
+func (c *OmadaClient) BlockClient(siteId string, clientMac MAC) error {
+	return c.clientAction(siteId, clientMac, "block")
+}
+
+func (c *OmadaClient) UnblockClient(siteId string, clientMac MAC) error {
+	return c.clientAction(siteId, clientMac, "unblock")
+}
+
+// ReconnectClient forces a wireless client to disconnect and associate again
+func (c *OmadaClient) ReconnectClient(siteId string, clientMac MAC) error {
+	return c.clientAction(siteId, clientMac, "reconnect")
+}
+
+func (c *OmadaClient) clientAction(siteId string, clientMac MAC, action string) error {
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/clients/%s/%s", c.ActiveBaseUrl(), c.omadaCId, siteId, clientMac, action)
+	request, err := http.NewRequest("POST", path, nil)
+	if err != nil {
+		return err
+	}
+
+	response := &EnvelopeResponse{}
+	err = c.httpDoMutating(request, response, CacheEndpointClientList, CacheEndpointClientInfo)
+	if err != nil {
+		return err
+	}
+	if err := response.Err(); err != nil {
+		return c.clientActionError(siteId, clientMac, err)
+	}
+	return nil
+}
+
+// clientActionError adds ErrClientNotFound to a failed action's error when the site has no record of the client.
+// The controller reports an unknown client with a general error code, so the only way to tell is to look.
+func (c *OmadaClient) clientActionError(siteId string, clientMac MAC, err error) error {
+	if errors.Is(err, ErrPermissionDenied) {
+		return err
+	}
+	known, lookupErr := c.clientExists(siteId, clientMac)
+	if lookupErr != nil || known {
+		return err
+	}
+	return fmt.Errorf("%w: %s on site %s: %w", ErrClientNotFound, clientMac, siteId, err)
+}
+
+// clientExists checks the connected clients, then the site's history for offline ones such as blocked clients
+func (c *OmadaClient) clientExists(siteId string, clientMac MAC) (bool, error) {
+	info, err := c.GetClientInfo(siteId, clientMac)
+	if err != nil {
+		return false, err
+	}
+	if info.Err() == nil && info.Result.MAC == clientMac {
+		return true, nil
+	}
+
+	knownClients, err := c.GetAllKnownClients(siteId, KnownClientQuery{SearchKey: clientMac.String()})
+	if err != nil {
+		return false, err
+	}
+	for _, knownClient := range knownClients {
+		if knownClient.MAC == clientMac {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *OmadaClient) RenameClient(siteId string, clientMac MAC, name string) error {
+	return c.updateClientSetting(siteId, clientMac, "name", map[string]string{"name": name})
+}
+
+// SetClientFixedAddr reserves addr for the client via DHCP on the network netId
+func (c *OmadaClient) SetClientFixedAddr(siteId string, clientMac MAC, netId string, addr netip.Addr) error {
+	if !addr.Is4() {
+		return fmt.Errorf("fixed address must be IPv4, got %s", addr)
+	}
+	return c.UpdateClientIpSetting(siteId, clientMac, ClientIpSetting{UseFixedAddr: true, NetId: netId, IP: addr.String()})
+}
+
+func (c *OmadaClient) ClearClientFixedAddr(siteId string, clientMac MAC) error {
+	return c.UpdateClientIpSetting(siteId, clientMac, ClientIpSetting{UseFixedAddr: false})
+}
+
+func (c *OmadaClient) UpdateClientIpSetting(siteId string, clientMac MAC, setting ClientIpSetting) error {
+	return c.updateClientSetting(siteId, clientMac, "ip-setting", setting)
+}
+
+func (c *OmadaClient) SetClientRateLimitProfile(siteId string, clientMac MAC, rateLimitProfileId string) error {
+	return c.UpdateClientRateLimit(siteId, clientMac, ClientRateLimit{Mode: RateLimitModeProfile, RateLimitProfileId: rateLimitProfileId})
+}
+
+func (c *OmadaClient) SetClientCustomRateLimit(siteId string, clientMac MAC, limit CustomRateLimit) error {
+	return c.UpdateClientRateLimit(siteId, clientMac, ClientRateLimit{Mode: RateLimitModeCustom, CustomRateLimit: limit})
+}
+
+func (c *OmadaClient) UpdateClientRateLimit(siteId string, clientMac MAC, rateLimit ClientRateLimit) error {
+	return c.updateClientSetting(siteId, clientMac, "ratelimit", rateLimit)
+}
+
+// SetClientLockToAp restricts the client to associating with the given APs. Only the AP MACs are required.
+func (c *OmadaClient) SetClientLockToAp(siteId string, clientMac MAC, setting ClientLockToApSetting) error {
+	if setting.Enable && len(setting.APs) == 0 {
+		return fmt.Errorf("at least one AP is required to lock a client to")
+	}
+	return c.updateClientSetting(siteId, clientMac, "lock-to-ap", setting)
+}
+
+func (c *OmadaClient) updateClientSetting(siteId string, clientMac MAC, setting string, payload interface{}) error {
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/clients/%s/%s", c.ActiveBaseUrl(), c.omadaCId, siteId, clientMac, setting)
+	request, err := http.NewRequest("PATCH", path, bytes.NewReader(encodedPayload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response := &EnvelopeResponse{}
+	err = c.httpDoMutating(request, response, CacheEndpointClientList, CacheEndpointClientInfo)
+	if err != nil {
+		return err
+	}
+	return response.Err()
+}
+
+type ClientSortField string
+
+const (
+	ClientSortByName     ClientSortField = "name"
+	ClientSortByMAC      ClientSortField = "mac"
+	ClientSortByIP       ClientSortField = "ip"
+	ClientSortByUptime   ClientSortField = "uptime"
+	ClientSortByActivity ClientSortField = "activity"
+	ClientSortByTraffic  ClientSortField = "traffic"
+	ClientSortByRSSI     ClientSortField = "rssi"
+)
+
+type ClientSort struct {
+	Field      ClientSortField
+	Descending bool
+}
+
+// ClientListQuery narrows down GetClientListWithQuery. Unset fields aren't sent, pointers are used where the zero
+// value is a meaningful filter.
+type ClientListQuery struct {
+	SearchKey string
+	Wireless  *bool
+	Guest     *bool
+	SSID      string
+	APMac     MAC
+	VID       *int
+	RadioId   *RadioId
+	Sorts     []ClientSort
+}
+
+// encode returns the query parameters with a leading &, keeping sorts in the order given since it's significant
+func (q ClientListQuery) encode() string {
+	params := url.Values{}
+	if q.SearchKey != "" {
+		params.Set("searchKey", q.SearchKey)
+	}
+	if q.Wireless != nil {
+		params.Set("filters.wireless", strconv.FormatBool(*q.Wireless))
+	}
+	if q.Guest != nil {
+		params.Set("filters.guest", strconv.FormatBool(*q.Guest))
+	}
+	if q.SSID != "" {
+		params.Set("filters.ssid", q.SSID)
+	}
+	if !q.APMac.IsZero() {
+		params.Set("filters.apMac", q.APMac.String())
+	}
+	if q.VID != nil {
+		params.Set("filters.vid", strconv.Itoa(*q.VID))
+	}
+	if q.RadioId != nil {
+		params.Set("filters.radioId", strconv.Itoa(int(*q.RadioId)))
+	}
+
+	var encoded strings.Builder
+	if len(params) > 0 {
+		encoded.WriteString("&")
+		encoded.WriteString(params.Encode())
+	}
+	for _, sort := range q.Sorts {
+		direction := "asc"
+		if sort.Descending {
+			direction = "desc"
+		}
+		encoded.WriteString(fmt.Sprintf("&sorts.%s=%s", url.QueryEscape(string(sort.Field)), direction))
+	}
+	return encoded.String()
+}
+
 type GetClientListResponse struct {
 	EnvelopeResponse
 	Result struct {
@@ -38,128 +271,144 @@ type GetClientListResponse struct {
 		CurrentPage int32        `json:"currentPage"`
 		CurrentSize int32        `json:"currentSize"`
 		Data        []ClientInfo `json:"data"`
-		ClientStat  struct {
-			Total            int `json:"total"`
-			Wireless         int `json:"wireless"`
-			Wired            int `json:"wired"`
-			Num2g            int `json:"num2g"`
-			Num5g            int `json:"num5g"`
-			Num6g            int `json:"num6g"`
-			NumUser          int `json:"numUser"`
-			NumGuest         int `json:"numGuest"`
-			NumWirelessUser  int `json:"numWirelessUser"`
-			NumWirelessGuest int `json:"numWirelessGuest"`
-			Num2gUser        int `json:"num2gUser"`
-			Num5gUser        int `json:"num5gUser"`
-			Num6gUser        int `json:"num6gUser"`
-			Num2gGuest       int `json:"num2gGuest"`
-			Num5gGuest       int `json:"num5gGuest"`
-			Num6gGuest       int `json:"num6gGuest"`
-			Poor             int `json:"poor"`
-			Fair             int `json:"fair"`
-			NoData           int `json:"noData"`
-			Good             int `json:"good"`
-		} `json:"clientStat"`
+		ClientStat  ClientStat   `json:"clientStat"`
 	} `json:"result"`
 }
 
+type ClientStat struct {
+	Total            int `json:"total"`
+	Wireless         int `json:"wireless"`
+	Wired            int `json:"wired"`
+	Num2g            int `json:"num2g"`
+	Num5g            int `json:"num5g"`
+	Num6g            int `json:"num6g"`
+	NumUser          int `json:"numUser"`
+	NumGuest         int `json:"numGuest"`
+	NumWirelessUser  int `json:"numWirelessUser"`
+	NumWirelessGuest int `json:"numWirelessGuest"`
+	Num2gUser        int `json:"num2gUser"`
+	Num5gUser        int `json:"num5gUser"`
+	Num6gUser        int `json:"num6gUser"`
+	Num2gGuest       int `json:"num2gGuest"`
+	Num5gGuest       int `json:"num5gGuest"`
+	Num6gGuest       int `json:"num6gGuest"`
+	Poor             int `json:"poor"`
+	Fair             int `json:"fair"`
+	NoData           int `json:"noData"`
+	Good             int `json:"good"`
+}
+
 type GetClientInfoResponse struct {
 	EnvelopeResponse
 	Result ClientInfo `json:"result"`
 }
 
 type ClientInfo struct {
-	Id                        string   `json:"id"`
-	MAC                       string   `json:"mac"`
-	Name                      string   `json:"name"`
-	HostName                  string   `json:"hostName"`
-	Vendor                    string   `json:"vendor"`
-	DeviceType                string   `json:"deviceType"`
-	DeviceCategory            string   `json:"deviceCategory"`
-	OsName                    string   `json:"osName"`
-	IP                        string   `json:"ip"`
-	IPv6List                  []string `json:"ipv6List"`
-	ConnectType               int      `json:"connectType"`
-	ConnectDevType            string   `json:"connectDevType"`
-	ConnectedToWirelessRouter bool     `json:"connectedToWirelessRouter"`
-	Wireless                  bool     `json:"wireless"`
-	SSID                      string   `json:"ssid"`
-	SignalLevel               int      `json:"signalLevel"`
-	HealthScore               int      `json:"healthScore"`
-	SignalRank                int      `json:"signalRank"`
-	WifiMode                  int      `json:"wifiMode"`
-	APName                    string   `json:"apName"`
-	APMac                     string   `json:"apMac"`
-	RadioId                   int      `json:"radioId"`
-	Channel                   int      `json:"channel"`
-	RxRate                    int      `json:"rxRate"`
-	TxRate                    int      `json:"txRate"`
-	PowerSave                 bool     `json:"powerSave"`
-	RSSI                      int      `json:"rssi"`
-	SNR                       int      `json:"snr"`
-	SwitchMac                 string   `json:"switchMac"`
-	SwitchName                string   `json:"switchName"`
-	GatewayMac                string   `json:"gatewayMac"`
-	GatewayName               string   `json:"gatewayName"`
-	VID                       int      `json:"vid"`
-	NetworkName               string   `json:"networkName"`
-	Dot1xIdentity             string   `json:"dot1xIdentity"`
-	Dot1xVlan                 int      `json:"dot1xVlan"`
-	Port                      int      `json:"port"`
-	LagID                     int      `json:"lagId"`
-	Activity                  int      `json:"activity"`
-	TrafficDown               int      `json:"trafficDown"`
-	TrafficUp                 int      `json:"trafficUp"`
-	Uptime                    int      `json:"uptime"`
-	LastSeen                  int      `json:"lastSeen"`
-	AuthStatus                int      `json:"authStatus"`
-	Blocked                   bool     `json:"blocked"`
-	Guest                     bool     `json:"guest"`
-	Active                    bool     `json:"active"`
-	Manager                   bool     `json:"manager"`
-	IpSetting                 struct {
-		UseFixedAddr bool   `json:"useFixedAddr"`
-		NetId        string `json:"netId"`
-		IP           string `json:"ip"`
-	} `json:"ipSetting"`
-	DownPacket int `json:"downPacket"`
-	UpPacket   int `json:"upPacket"`
-	RateLimit  struct {
-		Mode               int    `json:"mode"`
-		RateLimitProfileId string `json:"rateLimitProfileId"`
-		CustomRateLimit    struct {
-			DownLimit       int  `json:"downLimit"`
-			DownLimitEnable bool `json:"downLimitEnable"`
-			UpLimit         int  `json:"upLimit"`
-			UpLimitEnable   bool `json:"upLimitEnable"`
-		} `json:"customRateLimit"`
-	} `json:"rateLimit"`
-	ClientLockToApSetting struct {
-		Enable bool `json:"enable"`
-		APs    []struct {
-			Name string `json:"name"`
-			MAC  string `json:"mac"`
-		} `json:"aps"`
-	} `json:"clientLockToApSetting"`
-	Support5g2 bool `json:"support5g2"`
-	MultiLink  []struct {
-		RadioId            int  `json:"radioId"`
-		WifiMode           int  `json:"wifiMode"`
-		Channel            int  `json:"channel"`
-		RxRate             int  `json:"rxRate"`
-		TxRate             int  `json:"txRate"`
-		PowerSave          bool `json:"powerSave"`
-		RSSI               int  `json:"rssi"`
-		SNR                int  `json:"snr"`
-		SignalLevel        int  `json:"signalLevel"`
-		SignalRank         int  `json:"signalRank"`
-		UpPacket           int  `json:"upPacket"`
-		DownPacket         int  `json:"downPacket"`
-		TrafficDown        int  `json:"trafficDown"`
-		TrafficUp          int  `json:"trafficUp"`
-		Activity           int  `json:"activity"`
-		SignalLevelAndRank int  `json:"signalLevelAndRank"`
-	} `json:"multiLink"`
-	Unit         int    `json:"unit"`
-	StandardPort string `json:"standardPort"`
+	Id                        string                `json:"id"`
+	MAC                       MAC                   `json:"mac"`
+	Name                      string                `json:"name"`
+	HostName                  string                `json:"hostName"`
+	Vendor                    string                `json:"vendor"`
+	DeviceType                string                `json:"deviceType"`
+	DeviceCategory            string                `json:"deviceCategory"`
+	OsName                    string                `json:"osName"`
+	IP                        string                `json:"ip"`
+	IPv6List                  []string              `json:"ipv6List"`
+	ConnectType               ConnectType           `json:"connectType"`
+	ConnectDevType            string                `json:"connectDevType"`
+	ConnectedToWirelessRouter bool                  `json:"connectedToWirelessRouter"`
+	Wireless                  bool                  `json:"wireless"`
+	SSID                      string                `json:"ssid"`
+	SignalLevel               int                   `json:"signalLevel"`
+	HealthScore               int                   `json:"healthScore"`
+	SignalRank                int                   `json:"signalRank"`
+	WifiMode                  WifiMode              `json:"wifiMode"`
+	APName                    string                `json:"apName"`
+	APMac                     MAC                   `json:"apMac"`
+	RadioId                   RadioId               `json:"radioId"`
+	Channel                   int                   `json:"channel"`
+	RxRate                    int                   `json:"rxRate"`
+	TxRate                    int                   `json:"txRate"`
+	PowerSave                 bool                  `json:"powerSave"`
+	RSSI                      int                   `json:"rssi"`
+	SNR                       int                   `json:"snr"`
+	SwitchMac                 MAC                   `json:"switchMac"`
+	SwitchName                string                `json:"switchName"`
+	GatewayMac                MAC                   `json:"gatewayMac"`
+	GatewayName               string                `json:"gatewayName"`
+	VID                       int                   `json:"vid"`
+	NetworkName               string                `json:"networkName"`
+	Dot1xIdentity             string                `json:"dot1xIdentity"`
+	Dot1xVlan                 int                   `json:"dot1xVlan"`
+	Port                      int                   `json:"port"`
+	LagID                     int                   `json:"lagId"`
+	Activity                  Activity              `json:"activity"`
+	TrafficDown               int                   `json:"trafficDown"`
+	TrafficUp                 int                   `json:"trafficUp"`
+	Uptime                    int                   `json:"uptime"`
+	LastSeen                  int                   `json:"lastSeen"`
+	AuthStatus                AuthStatus            `json:"authStatus"`
+	Blocked                   bool                  `json:"blocked"`
+	Guest                     bool                  `json:"guest"`
+	Active                    bool                  `json:"active"`
+	Manager                   bool                  `json:"manager"`
+	IpSetting                 ClientIpSetting       `json:"ipSetting"`
+	DownPacket                int                   `json:"downPacket"`
+	UpPacket                  int                   `json:"upPacket"`
+	RateLimit                 ClientRateLimit       `json:"rateLimit"`
+	ClientLockToApSetting     ClientLockToApSetting `json:"clientLockToApSetting"`
+	Support5g2                bool                  `json:"support5g2"`
+	MultiLink                 []ClientLink          `json:"multiLink"`
+	Unit                      int                   `json:"unit"`
+	StandardPort              string                `json:"standardPort"`
+}
+
+// ClientLink is one of the links of a Wi-Fi 7 multi-link (MLO) client
+type ClientLink struct {
+	RadioId            RadioId  `json:"radioId"`
+	WifiMode           WifiMode `json:"wifiMode"`
+	Channel            int      `json:"channel"`
+	RxRate             int      `json:"rxRate"`
+	TxRate             int      `json:"txRate"`
+	PowerSave          bool     `json:"powerSave"`
+	RSSI               int      `json:"rssi"`
+	SNR                int      `json:"snr"`
+	SignalLevel        int      `json:"signalLevel"`
+	SignalRank         int      `json:"signalRank"`
+	UpPacket           int      `json:"upPacket"`
+	DownPacket         int      `json:"downPacket"`
+	TrafficDown        int      `json:"trafficDown"`
+	TrafficUp          int      `json:"trafficUp"`
+	Activity           Activity `json:"activity"`
+	SignalLevelAndRank int      `json:"signalLevelAndRank"`
+}
+
+type ClientIpSetting struct {
+	UseFixedAddr bool   `json:"useFixedAddr"`
+	NetId        string `json:"netId"`
+	IP           string `json:"ip"`
+}
+
+type ClientRateLimit struct {
+	Mode               RateLimitMode   `json:"mode"`
+	RateLimitProfileId string          `json:"rateLimitProfileId"`
+	CustomRateLimit    CustomRateLimit `json:"customRateLimit"`
+}
+
+// CustomRateLimit limits are in Kbps
+type CustomRateLimit struct {
+	DownLimit       int  `json:"downLimit"`
+	DownLimitEnable bool `json:"downLimitEnable"`
+	UpLimit         int  `json:"upLimit"`
+	UpLimitEnable   bool `json:"upLimitEnable"`
+}
+
+type ClientLockToApSetting struct {
+	Enable bool           `json:"enable"`
+	APs    []ClientLockAP `json:"aps"`
+}
+
+type ClientLockAP struct {
+	Name string `json:"name"`
+	MAC  MAC    `json:"mac"`
 }
@@ -1,10 +1,15 @@
 package omada
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
@@ -157,7 +162,7 @@ func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
 	assert.Equal(t, clientList.Result.CurrentSize, int32(100))
 	assert.Equal(t, clientList.Result.CurrentPage, int32(1))
 	assert.Equal(t, clientList.Result.Data[0].Id, "Some ID")
-	assert.Equal(t, clientList.Result.Data[0].MAC, "AA-BB-CC-DD-EE-FF")
+	assert.Equal(t, clientList.Result.Data[0].MAC, MustParseMAC("AA-BB-CC-DD-EE-FF"))
 	assert.Equal(t, clientList.Result.Data[0].Name, "My Device")
 	assert.Equal(t, clientList.Result.Data[0].HostName, "My Device Hostname")
 	assert.Equal(t, clientList.Result.Data[0].Vendor, "My Vendor")
@@ -166,7 +171,7 @@ func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
 	assert.Equal(t, clientList.Result.Data[0].OsName, "Some OS")
 	assert.Equal(t, clientList.Result.Data[0].IP, "192.168.1.100")
 	assert.Equal(t, clientList.Result.Data[0].IPv6List[0], "2001:0db8:85a3:0000:0000:8a2e:0370:7334")
-	assert.Equal(t, clientList.Result.Data[0].ConnectType, 1)
+	assert.Equal(t, clientList.Result.Data[0].ConnectType, ConnectTypeWirelessUser)
 	assert.Equal(t, clientList.Result.Data[0].ConnectDevType, "ap")
 	assert.Equal(t, clientList.Result.Data[0].ConnectedToWirelessRouter, false)
 	assert.Equal(t, clientList.Result.Data[0].Wireless, true)
@@ -174,19 +179,19 @@ func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
 	assert.Equal(t, clientList.Result.Data[0].SignalLevel, 85)
 	assert.Equal(t, clientList.Result.Data[0].HealthScore, -1)
 	assert.Equal(t, clientList.Result.Data[0].SignalRank, 4)
-	assert.Equal(t, clientList.Result.Data[0].WifiMode, 5)
+	assert.Equal(t, clientList.Result.Data[0].WifiMode, WifiMode11ac)
 	assert.Equal(t, clientList.Result.Data[0].APName, "My Access Point")
-	assert.Equal(t, clientList.Result.Data[0].APMac, "11-22-33-44-55-66")
-	assert.Equal(t, clientList.Result.Data[0].RadioId, 1)
+	assert.Equal(t, clientList.Result.Data[0].APMac, MustParseMAC("11-22-33-44-55-66"))
+	assert.Equal(t, clientList.Result.Data[0].RadioId, RadioId5g)
 	assert.Equal(t, clientList.Result.Data[0].Channel, 36)
 	assert.Equal(t, clientList.Result.Data[0].RxRate, 780000)
 	assert.Equal(t, clientList.Result.Data[0].TxRate, 560000)
 	assert.Equal(t, clientList.Result.Data[0].PowerSave, false)
 	assert.Equal(t, clientList.Result.Data[0].RSSI, -56)
 	assert.Equal(t, clientList.Result.Data[0].SNR, 39)
-	assert.Equal(t, clientList.Result.Data[0].SwitchMac, "44-55-44-55-44-55")
+	assert.Equal(t, clientList.Result.Data[0].SwitchMac, MustParseMAC("44-55-44-55-44-55"))
 	assert.Equal(t, clientList.Result.Data[0].SwitchName, "My Switch")
-	assert.Equal(t, clientList.Result.Data[0].GatewayMac, "66-77-66-77-66-77")
+	assert.Equal(t, clientList.Result.Data[0].GatewayMac, MustParseMAC("66-77-66-77-66-77"))
 	assert.Equal(t, clientList.Result.Data[0].GatewayName, "My Gateway")
 	assert.Equal(t, clientList.Result.Data[0].VID, 0)
 	assert.Equal(t, clientList.Result.Data[0].NetworkName, "My Network Name")
@@ -194,12 +199,12 @@ func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
 	assert.Equal(t, clientList.Result.Data[0].Dot1xVlan, 4)
 	assert.Equal(t, clientList.Result.Data[0].Port, 16)
 	assert.Equal(t, clientList.Result.Data[0].LagID, 2)
-	assert.Equal(t, clientList.Result.Data[0].Activity, 246)
+	assert.Equal(t, clientList.Result.Data[0].Activity, Activity(246))
 	assert.Equal(t, clientList.Result.Data[0].TrafficDown, 159561759)
 	assert.Equal(t, clientList.Result.Data[0].TrafficUp, 121189972)
 	assert.Equal(t, clientList.Result.Data[0].Uptime, 291632)
 	assert.Equal(t, clientList.Result.Data[0].LastSeen, 1698744232047)
-	assert.Equal(t, clientList.Result.Data[0].AuthStatus, 0)
+	assert.Equal(t, clientList.Result.Data[0].AuthStatus, AuthStatusConnected)
 	assert.Equal(t, clientList.Result.Data[0].Blocked, false)
 	assert.Equal(t, clientList.Result.Data[0].Guest, false)
 	assert.Equal(t, clientList.Result.Data[0].Active, true)
@@ -209,7 +214,7 @@ func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
 	assert.Equal(t, clientList.Result.Data[0].IpSetting.IP, "10.2.2.2")
 	assert.Equal(t, clientList.Result.Data[0].DownPacket, 1598847)
 	assert.Equal(t, clientList.Result.Data[0].UpPacket, 1546297)
-	assert.Equal(t, clientList.Result.Data[0].RateLimit.Mode, 1)
+	assert.Equal(t, clientList.Result.Data[0].RateLimit.Mode, RateLimitModeProfile)
 	assert.Equal(t, clientList.Result.Data[0].RateLimit.RateLimitProfileId, "Rate limit profileId")
 	assert.Equal(t, clientList.Result.Data[0].RateLimit.CustomRateLimit.DownLimitEnable, true)
 	assert.Equal(t, clientList.Result.Data[0].RateLimit.CustomRateLimit.DownLimit, 8000000)
@@ -217,10 +222,10 @@ func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
 	assert.Equal(t, clientList.Result.Data[0].RateLimit.CustomRateLimit.UpLimit, 6000000)
 	assert.Equal(t, clientList.Result.Data[0].ClientLockToApSetting.Enable, true)
 	assert.Equal(t, clientList.Result.Data[0].ClientLockToApSetting.APs[0].Name, "Locked AP Name")
-	assert.Equal(t, clientList.Result.Data[0].ClientLockToApSetting.APs[0].MAC, "22-22-22-22-22-22")
+	assert.Equal(t, clientList.Result.Data[0].ClientLockToApSetting.APs[0].MAC, MustParseMAC("22-22-22-22-22-22"))
 	assert.Equal(t, clientList.Result.Data[0].Support5g2, true)
-	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].RadioId, 5)
-	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].WifiMode, 2)
+	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].RadioId, RadioId(5))
+	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].WifiMode, WifiMode11g)
 	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].Channel, 44)
 	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].RxRate, 80000000)
 	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].TxRate, 70000000)
@@ -233,7 +238,7 @@ func TestOmadaClient_GetClientList_ReturnsAValidClientList(t *testing.T) {
 	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].DownPacket, 456456)
 	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].TrafficDown, 456456456)
 	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].TrafficUp, 123123123)
-	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].Activity, 552234)
+	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].Activity, Activity(552234))
 	assert.Equal(t, clientList.Result.Data[0].MultiLink[0].SignalLevelAndRank, 77)
 	assert.Equal(t, clientList.Result.Data[0].Unit, 82)
 	assert.Equal(t, clientList.Result.Data[0].StandardPort, "Std port string")
@@ -370,13 +375,13 @@ func TestOmadaClient_GetClientInfo_ReturnsAValidClientInfo(t *testing.T) {
 	defer server.Close()
 
 	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
-	clientInfo, err := c.GetClientInfo("me-site", "11-22-33-44-55-66")
+	clientInfo, err := c.GetClientInfo("me-site", MustParseMAC("11:22:33:44:55:66"))
 
 	assert.NoError(t, err)
 	assert.Equal(t, clientInfo.ErrorCode, 0)
 	assert.Equal(t, clientInfo.Message, "Success.")
 	assert.Equal(t, clientInfo.Result.Id, "Some ID")
-	assert.Equal(t, clientInfo.Result.MAC, "AA-BB-CC-DD-EE-FF")
+	assert.Equal(t, clientInfo.Result.MAC, MustParseMAC("AA-BB-CC-DD-EE-FF"))
 	assert.Equal(t, clientInfo.Result.Name, "My Device")
 	assert.Equal(t, clientInfo.Result.HostName, "My Device Hostname")
 	assert.Equal(t, clientInfo.Result.Vendor, "My Vendor")
@@ -385,7 +390,7 @@ func TestOmadaClient_GetClientInfo_ReturnsAValidClientInfo(t *testing.T) {
 	assert.Equal(t, clientInfo.Result.OsName, "Some OS")
 	assert.Equal(t, clientInfo.Result.IP, "192.168.1.100")
 	assert.Equal(t, clientInfo.Result.IPv6List[0], "2001:0db8:85a3:0000:0000:8a2e:0370:7334")
-	assert.Equal(t, clientInfo.Result.ConnectType, 1)
+	assert.Equal(t, clientInfo.Result.ConnectType, ConnectTypeWirelessUser)
 	assert.Equal(t, clientInfo.Result.ConnectDevType, "ap")
 	assert.Equal(t, clientInfo.Result.ConnectedToWirelessRouter, false)
 	assert.Equal(t, clientInfo.Result.Wireless, true)
@@ -393,19 +398,19 @@ func TestOmadaClient_GetClientInfo_ReturnsAValidClientInfo(t *testing.T) {
 	assert.Equal(t, clientInfo.Result.SignalLevel, 85)
 	assert.Equal(t, clientInfo.Result.HealthScore, -1)
 	assert.Equal(t, clientInfo.Result.SignalRank, 4)
-	assert.Equal(t, clientInfo.Result.WifiMode, 5)
+	assert.Equal(t, clientInfo.Result.WifiMode, WifiMode11ac)
 	assert.Equal(t, clientInfo.Result.APName, "My Access Point")
-	assert.Equal(t, clientInfo.Result.APMac, "11-22-33-44-55-66")
-	assert.Equal(t, clientInfo.Result.RadioId, 1)
+	assert.Equal(t, clientInfo.Result.APMac, MustParseMAC("11-22-33-44-55-66"))
+	assert.Equal(t, clientInfo.Result.RadioId, RadioId5g)
 	assert.Equal(t, clientInfo.Result.Channel, 36)
 	assert.Equal(t, clientInfo.Result.RxRate, 780000)
 	assert.Equal(t, clientInfo.Result.TxRate, 560000)
 	assert.Equal(t, clientInfo.Result.PowerSave, false)
 	assert.Equal(t, clientInfo.Result.RSSI, -56)
 	assert.Equal(t, clientInfo.Result.SNR, 39)
-	assert.Equal(t, clientInfo.Result.SwitchMac, "44-55-44-55-44-55")
+	assert.Equal(t, clientInfo.Result.SwitchMac, MustParseMAC("44-55-44-55-44-55"))
 	assert.Equal(t, clientInfo.Result.SwitchName, "My Switch")
-	assert.Equal(t, clientInfo.Result.GatewayMac, "66-77-66-77-66-77")
+	assert.Equal(t, clientInfo.Result.GatewayMac, MustParseMAC("66-77-66-77-66-77"))
 	assert.Equal(t, clientInfo.Result.GatewayName, "My Gateway")
 	assert.Equal(t, clientInfo.Result.VID, 0)
 	assert.Equal(t, clientInfo.Result.NetworkName, "My Network Name")
@@ -413,12 +418,12 @@ func TestOmadaClient_GetClientInfo_ReturnsAValidClientInfo(t *testing.T) {
 	assert.Equal(t, clientInfo.Result.Dot1xVlan, 4)
 	assert.Equal(t, clientInfo.Result.Port, 16)
 	assert.Equal(t, clientInfo.Result.LagID, 2)
-	assert.Equal(t, clientInfo.Result.Activity, 246)
+	assert.Equal(t, clientInfo.Result.Activity, Activity(246))
 	assert.Equal(t, clientInfo.Result.TrafficDown, 159561759)
 	assert.Equal(t, clientInfo.Result.TrafficUp, 121189972)
 	assert.Equal(t, clientInfo.Result.Uptime, 291632)
 	assert.Equal(t, clientInfo.Result.LastSeen, 1698744232047)
-	assert.Equal(t, clientInfo.Result.AuthStatus, 0)
+	assert.Equal(t, clientInfo.Result.AuthStatus, AuthStatusConnected)
 	assert.Equal(t, clientInfo.Result.Blocked, false)
 	assert.Equal(t, clientInfo.Result.Guest, false)
 	assert.Equal(t, clientInfo.Result.Active, true)
@@ -428,7 +433,7 @@ func TestOmadaClient_GetClientInfo_ReturnsAValidClientInfo(t *testing.T) {
 	assert.Equal(t, clientInfo.Result.IpSetting.IP, "10.2.2.2")
 	assert.Equal(t, clientInfo.Result.DownPacket, 1598847)
 	assert.Equal(t, clientInfo.Result.UpPacket, 1546297)
-	assert.Equal(t, clientInfo.Result.RateLimit.Mode, 1)
+	assert.Equal(t, clientInfo.Result.RateLimit.Mode, RateLimitModeProfile)
 	assert.Equal(t, clientInfo.Result.RateLimit.RateLimitProfileId, "Rate limit profileId")
 	assert.Equal(t, clientInfo.Result.RateLimit.CustomRateLimit.DownLimitEnable, true)
 	assert.Equal(t, clientInfo.Result.RateLimit.CustomRateLimit.DownLimit, 8000000)
@@ -436,10 +441,10 @@ func TestOmadaClient_GetClientInfo_ReturnsAValidClientInfo(t *testing.T) {
 	assert.Equal(t, clientInfo.Result.RateLimit.CustomRateLimit.UpLimit, 6000000)
 	assert.Equal(t, clientInfo.Result.ClientLockToApSetting.Enable, true)
 	assert.Equal(t, clientInfo.Result.ClientLockToApSetting.APs[0].Name, "Locked AP Name")
-	assert.Equal(t, clientInfo.Result.ClientLockToApSetting.APs[0].MAC, "22-22-22-22-22-22")
+	assert.Equal(t, clientInfo.Result.ClientLockToApSetting.APs[0].MAC, MustParseMAC("22-22-22-22-22-22"))
 	assert.Equal(t, clientInfo.Result.Support5g2, true)
-	assert.Equal(t, clientInfo.Result.MultiLink[0].RadioId, 5)
-	assert.Equal(t, clientInfo.Result.MultiLink[0].WifiMode, 2)
+	assert.Equal(t, clientInfo.Result.MultiLink[0].RadioId, RadioId(5))
+	assert.Equal(t, clientInfo.Result.MultiLink[0].WifiMode, WifiMode11g)
 	assert.Equal(t, clientInfo.Result.MultiLink[0].Channel, 44)
 	assert.Equal(t, clientInfo.Result.MultiLink[0].RxRate, 80000000)
 	assert.Equal(t, clientInfo.Result.MultiLink[0].TxRate, 70000000)
@@ -452,7 +457,237 @@ func TestOmadaClient_GetClientInfo_ReturnsAValidClientInfo(t *testing.T) {
 	assert.Equal(t, clientInfo.Result.MultiLink[0].DownPacket, 456456)
 	assert.Equal(t, clientInfo.Result.MultiLink[0].TrafficDown, 456456456)
 	assert.Equal(t, clientInfo.Result.MultiLink[0].TrafficUp, 123123123)
-	assert.Equal(t, clientInfo.Result.MultiLink[0].Activity, 552234)
+	assert.Equal(t, clientInfo.Result.MultiLink[0].Activity, Activity(552234))
 	assert.Equal(t, clientInfo.Result.MultiLink[0].SignalLevelAndRank, 77)
 	assert.Equal(t, clientInfo.Result.Unit, 82)
 }
+
+func TestOmadaClient_GetClientListWithQuery_SendsSearchFiltersAndSorts(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "2", query.Get("page"))
+		assert.Equal(t, "100", query.Get("pageSize"))
+		assert.Equal(t, "phone & tablet", query.Get("searchKey"))
+		assert.Equal(t, "true", query.Get("filters.wireless"))
+		assert.Equal(t, "false", query.Get("filters.guest"))
+		assert.Equal(t, "My SSID", query.Get("filters.ssid"))
+		assert.Equal(t, "11-22-33-44-55-66", query.Get("filters.apMac"))
+		assert.Equal(t, "0", query.Get("filters.vid"))
+		assert.Equal(t, "3", query.Get("filters.radioId"))
+		assert.Equal(t, "desc", query.Get("sorts.activity"))
+		assert.Equal(t, "asc", query.Get("sorts.name"))
+		assert.Less(t, strings.Index(r.URL.RawQuery, "sorts.activity"), strings.Index(r.URL.RawQuery, "sorts.name"))
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": {"totalRows": 0, "data": []}}`))
+		assert.NoError(t, err)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/other-site/clients", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "page=1&pageSize=100", r.URL.RawQuery)
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": {"totalRows": 0, "data": []}}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	wireless, guest, vid, radio := true, false, 0, RadioId6g
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	clientList, err := c.GetClientListWithQuery("me-site", 2, ClientListQuery{
+		SearchKey: "phone & tablet",
+		Wireless:  &wireless,
+		Guest:     &guest,
+		SSID:      "My SSID",
+		APMac:     MustParseMAC("11:22:33:44:55:66"),
+		VID:       &vid,
+		RadioId:   &radio,
+		Sorts:     []ClientSort{{Field: ClientSortByActivity, Descending: true}, {Field: ClientSortByName}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, clientList.ErrorCode)
+
+	_, err = c.GetClientListWithQuery("other-site", 1, ClientListQuery{})
+	assert.NoError(t, err)
+}
+
+func TestOmadaClient_BlockUnblockAndReconnectClient_PostToTheClientAction(t *testing.T) {
+	var actions []string
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients/AA-BB-CC-DD-EE-FF/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "AccessToken=my-token", r.Header.Get("Authorization"))
+		actions = append(actions, strings.TrimPrefix(r.URL.Path, "/openapi/v1/my-cid/sites/me-site/clients/AA-BB-CC-DD-EE-FF/"))
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success."}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	mac := MustParseMAC("aa:bb:cc:dd:ee:ff")
+
+	assert.NoError(t, c.BlockClient("me-site", mac))
+	assert.NoError(t, c.UnblockClient("me-site", mac))
+	assert.NoError(t, c.ReconnectClient("me-site", mac))
+	assert.Equal(t, []string{"block", "unblock", "reconnect"}, actions)
+}
+
+func TestOmadaClient_BlockClient_ReturnsTypedErrors(t *testing.T) {
+	lookups := 0
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients/", func(w http.ResponseWriter, r *http.Request) {
+		mac, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/openapi/v1/my-cid/sites/me-site/clients/"), "/")
+		var body string
+		switch {
+		case action == "block" && mac == "11-11-11-11-11-11":
+			body = `{"errorCode": -1005, "msg": "Operation forbidden."}`
+		case action == "block":
+			body = `{"errorCode": -1, "msg": "General error."}`
+		case mac == "33-33-33-33-33-33":
+			lookups++
+			body = `{"errorCode": 0, "result": {"mac": "33-33-33-33-33-33"}}`
+		default:
+			lookups++
+			body = `{"errorCode": -1, "msg": "General error."}`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/insight/clients", func(w http.ResponseWriter, r *http.Request) {
+		// Blocked clients are offline, so only the history knows about them
+		body := `{"errorCode": 0, "result": {"totalRows": 0, "data": []}}`
+		if r.URL.Query().Get("searchKey") == "44-44-44-44-44-44" {
+			body = `{"errorCode": 0, "result": {"totalRows": 1, "data": [{"mac": "44-44-44-44-44-44", "block": true}]}}`
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+
+	err := c.BlockClient("me-site", MustParseMAC("11:11:11:11:11:11"))
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	assert.NotErrorIs(t, err, ErrClientNotFound)
+	assert.EqualError(t, err, "error response: -1005: Operation forbidden.")
+	assert.Equal(t, 0, lookups)
+
+	err = c.BlockClient("me-site", MustParseMAC("22:22:22:22:22:22"))
+	assert.True(t, errors.Is(err, ErrClientNotFound))
+	assert.NotErrorIs(t, err, ErrPermissionDenied)
+	var apiError *ApiError
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, -1, apiError.ErrorCode)
+
+	// The client exists, so the failure was something else
+	assert.NotErrorIs(t, c.BlockClient("me-site", MustParseMAC("33:33:33:33:33:33")), ErrClientNotFound)
+	assert.NotErrorIs(t, c.BlockClient("me-site", MustParseMAC("44:44:44:44:44:44")), ErrClientNotFound)
+}
+
+func TestOmadaClient_BlockClient_ReturnsAnErrorForAnInvalidSiteId(t *testing.T) {
+	c := NewClient("http://localhost", "my-cid", "my-client-id", "my-client-secret", true)
+	assert.Error(t, c.BlockClient("me\x7fsite", MustParseMAC("11:11:11:11:11:11")))
+}
+
+func TestOmadaClient_BlockClient_InvalidatesCachedClients(t *testing.T) {
+	listRequests := 0
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients", func(w http.ResponseWriter, r *http.Request) {
+		listRequests++
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": {"totalRows": 0, "data": []}}`))
+		assert.NoError(t, err)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients/AA-BB-CC-DD-EE-FF/block", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success."}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointClientList: time.Minute})
+
+	_, err := c.GetClientList("me-site", 1)
+	assert.NoError(t, err)
+	_, err = c.GetClientList("me-site", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, listRequests)
+
+	assert.NoError(t, c.BlockClient("me-site", MustParseMAC("AA-BB-CC-DD-EE-FF")))
+	_, err = c.GetClientList("me-site", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, listRequests)
+}
+
+func TestOmadaClient_UpdateClientSettings_PatchesTheSettingWithAJsonPayload(t *testing.T) {
+	received := map[string]string{}
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients/AA-BB-CC-DD-EE-FF/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		received[strings.TrimPrefix(r.URL.Path, "/openapi/v1/my-cid/sites/me-site/clients/AA-BB-CC-DD-EE-FF/")] = string(body)
+		_, err = w.Write([]byte(`{"errorCode": 0, "msg": "Success."}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	mac := MustParseMAC("aa:bb:cc:dd:ee:ff")
+
+	assert.NoError(t, c.RenameClient("me-site", mac, "Kitchen Tablet"))
+	assert.JSONEq(t, `{"name": "Kitchen Tablet"}`, received["name"])
+
+	assert.NoError(t, c.SetClientFixedAddr("me-site", mac, "lan-net-id", netip.MustParseAddr("192.168.1.50")))
+	assert.JSONEq(t, `{"useFixedAddr": true, "netId": "lan-net-id", "ip": "192.168.1.50"}`, received["ip-setting"])
+	assert.NoError(t, c.ClearClientFixedAddr("me-site", mac))
+	assert.JSONEq(t, `{"useFixedAddr": false, "netId": "", "ip": ""}`, received["ip-setting"])
+
+	assert.NoError(t, c.SetClientRateLimitProfile("me-site", mac, "profile-id"))
+	assert.JSONEq(t, `{"mode": 1, "rateLimitProfileId": "profile-id", "customRateLimit": {"downLimit": 0, "downLimitEnable": false, "upLimit": 0, "upLimitEnable": false}}`, received["ratelimit"])
+	assert.NoError(t, c.SetClientCustomRateLimit("me-site", mac, CustomRateLimit{DownLimit: 8000, DownLimitEnable: true, UpLimit: 2000, UpLimitEnable: true}))
+	assert.JSONEq(t, `{"mode": 0, "rateLimitProfileId": "", "customRateLimit": {"downLimit": 8000, "downLimitEnable": true, "upLimit": 2000, "upLimitEnable": true}}`, received["ratelimit"])
+
+	assert.NoError(t, c.SetClientLockToAp("me-site", mac, ClientLockToApSetting{Enable: true, APs: []ClientLockAP{{MAC: MustParseMAC("11:22:33:44:55:66")}}}))
+	assert.JSONEq(t, `{"enable": true, "aps": [{"name": "", "mac": "11-22-33-44-55-66"}]}`, received["lock-to-ap"])
+}
+
+func TestOmadaClient_UpdateClientSettings_ValidatesArguments(t *testing.T) {
+	c := NewClient("http://unused.invalid", "my-cid", "my-client-id", "my-client-secret", true)
+	mac := MustParseMAC("aa:bb:cc:dd:ee:ff")
+
+	err := c.SetClientFixedAddr("me-site", mac, "lan-net-id", netip.MustParseAddr("2001:db8::1"))
+	assert.EqualError(t, err, "fixed address must be IPv4, got 2001:db8::1")
+	err = c.SetClientLockToAp("me-site", mac, ClientLockToApSetting{Enable: true})
+	assert.EqualError(t, err, "at least one AP is required to lock a client to")
+}
+
+func TestOmadaClient_UpdateClientSettings_ResendsThePayloadAfterATokenRefresh(t *testing.T) {
+	var bodies []string
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients/AA-BB-CC-DD-EE-FF/name", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			mockTokenExpiredResponse(t, w, r)
+			return
+		}
+		_, err = w.Write([]byte(`{"errorCode": 0, "msg": "Success."}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+
+	assert.NoError(t, c.RenameClient("me-site", MustParseMAC("aa:bb:cc:dd:ee:ff"), "Printer"))
+	assert.Equal(t, []string{`{"name":"Printer"}`, `{"name":"Printer"}`}, bodies)
+}
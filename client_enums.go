@@ -0,0 +1,192 @@
+package omada
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// The enums below are sent by the controller as plain integers and are marshalled back the same way. When
+// unmarshalling they also accept their String() form, so reports written with the names can be read back in.
+
+type ConnectType int
+
+const (
+	ConnectTypeWirelessGuest ConnectType = 0
+	ConnectTypeWirelessUser  ConnectType = 1
+	ConnectTypeWiredUser     ConnectType = 2
+)
+
+var connectTypeNames = map[ConnectType]string{
+	ConnectTypeWirelessGuest: "wireless guest",
+	ConnectTypeWirelessUser:  "wireless user",
+	ConnectTypeWiredUser:     "wired user",
+}
+
+func (t ConnectType) String() string { return enumString(t, connectTypeNames, "ConnectType") }
+
+func (t *ConnectType) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, connectTypeNames, t)
+}
+
+type WifiMode int
+
+const (
+	WifiMode11a    WifiMode = 0
+	WifiMode11b    WifiMode = 1
+	WifiMode11g    WifiMode = 2
+	WifiMode11na   WifiMode = 3
+	WifiMode11ng   WifiMode = 4
+	WifiMode11ac   WifiMode = 5
+	WifiMode11axa  WifiMode = 6
+	WifiMode11axg  WifiMode = 7
+	WifiMode11beg  WifiMode = 8
+	WifiMode11bea  WifiMode = 9
+	WifiMode11be6g WifiMode = 10
+)
+
+var wifiModeNames = map[WifiMode]string{
+	WifiMode11a:    "802.11a",
+	WifiMode11b:    "802.11b",
+	WifiMode11g:    "802.11g",
+	WifiMode11na:   "802.11n (5GHz)",
+	WifiMode11ng:   "802.11n (2.4GHz)",
+	WifiMode11ac:   "802.11ac",
+	WifiMode11axa:  "802.11ax (5GHz)",
+	WifiMode11axg:  "802.11ax (2.4GHz)",
+	WifiMode11beg:  "802.11be (2.4GHz)",
+	WifiMode11bea:  "802.11be (5GHz)",
+	WifiMode11be6g: "802.11be (6GHz)",
+}
+
+func (m WifiMode) String() string { return enumString(m, wifiModeNames, "WifiMode") }
+
+func (m *WifiMode) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, wifiModeNames, m)
+}
+
+// Generation is the Wi-Fi generation number of the mode, e.g. 6 for 802.11ax, or 0 if it predates the numbering
+func (m WifiMode) Generation() int {
+	switch m {
+	case WifiMode11na, WifiMode11ng:
+		return 4
+	case WifiMode11ac:
+		return 5
+	case WifiMode11axa, WifiMode11axg:
+		return 6
+	case WifiMode11beg, WifiMode11bea, WifiMode11be6g:
+		return 7
+	}
+	return 0
+}
+
+type RadioId int
+
+const (
+	RadioId2g  RadioId = 0
+	RadioId5g  RadioId = 1
+	RadioId5g2 RadioId = 2
+	RadioId6g  RadioId = 3
+)
+
+var radioIdNames = map[RadioId]string{
+	RadioId2g:  "2.4GHz",
+	RadioId5g:  "5GHz",
+	RadioId5g2: "5GHz-2",
+	RadioId6g:  "6GHz",
+}
+
+func (r RadioId) String() string { return enumString(r, radioIdNames, "RadioId") }
+
+func (r *RadioId) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, radioIdNames, r)
+}
+
+type AuthStatus int
+
+const (
+	AuthStatusConnected  AuthStatus = 0
+	AuthStatusPending    AuthStatus = 1
+	AuthStatusAuthorized AuthStatus = 2
+	AuthStatusAuthFree   AuthStatus = 3
+)
+
+var authStatusNames = map[AuthStatus]string{
+	AuthStatusConnected:  "connected",
+	AuthStatusPending:    "pending",
+	AuthStatusAuthorized: "authorized",
+	AuthStatusAuthFree:   "auth-free",
+}
+
+func (s AuthStatus) String() string { return enumString(s, authStatusNames, "AuthStatus") }
+
+func (s *AuthStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, authStatusNames, s)
+}
+
+type RateLimitMode int
+
+const (
+	RateLimitModeCustom  RateLimitMode = 0
+	RateLimitModeProfile RateLimitMode = 1
+)
+
+var rateLimitModeNames = map[RateLimitMode]string{
+	RateLimitModeCustom:  "custom",
+	RateLimitModeProfile: "profile",
+}
+
+func (m RateLimitMode) String() string { return enumString(m, rateLimitModeNames, "RateLimitMode") }
+
+func (m *RateLimitMode) UnmarshalJSON(data []byte) error {
+	return unmarshalEnumJSON(data, rateLimitModeNames, m)
+}
+
+// Activity is the client's current traffic in bytes per second. Unlike the other fields here it isn't a code,
+// it's typed so that it prints with units.
+type Activity int
+
+func (a Activity) String() string {
+	if a < 1000 {
+		return fmt.Sprintf("%d B/s", int(a))
+	}
+	value := float64(a) / 1000
+	for _, unit := range []string{"KB/s", "MB/s"} {
+		if value < 1000 {
+			return fmt.Sprintf("%.1f %s", value, unit)
+		}
+		value /= 1000
+	}
+	return fmt.Sprintf("%.1f GB/s", value)
+}
+
+func enumString[T ~int](value T, names map[T]string, typeName string) string {
+	if name, ok := names[value]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s(%d)", typeName, int(value))
+}
+
+func unmarshalEnumJSON[T ~int](data []byte, names map[T]string, out *T) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		*out = T(number)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("invalid enum value %s", string(data))
+	}
+	for value, candidate := range names {
+		if candidate == name {
+			*out = value
+			return nil
+		}
+	}
+	// Also accept a quoted number
+	if number, err := strconv.Atoi(name); err == nil {
+		*out = T(number)
+		return nil
+	}
+	return fmt.Errorf("unknown enum value %q", name)
+}
@@ -0,0 +1,56 @@
+package omada
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClientEnums_String(t *testing.T) {
+	assert.Equal(t, "wired user", ConnectTypeWiredUser.String())
+	assert.Equal(t, "802.11ax (5GHz)", WifiMode11axa.String())
+	assert.Equal(t, 6, WifiMode11axg.Generation())
+	assert.Equal(t, "5GHz", RadioId5g.String())
+	assert.Equal(t, "6GHz", RadioId6g.String())
+	assert.Equal(t, "RadioId(5)", RadioId(5).String())
+	assert.Equal(t, "authorized", AuthStatusAuthorized.String())
+	assert.Equal(t, "profile", RateLimitModeProfile.String())
+	assert.Equal(t, "246 B/s", Activity(246).String())
+	assert.Equal(t, "552.2 KB/s", Activity(552234).String())
+	assert.Equal(t, "1.5 GB/s", Activity(1500000000).String())
+}
+
+func TestClientEnums_JSONRoundTripsAsIntegers(t *testing.T) {
+	info := &ClientInfo{}
+	err := json.Unmarshal([]byte(`{"connectType": 2, "wifiMode": 9, "radioId": 3, "authStatus": 1, "activity": 100, "rateLimit": {"mode": 0}}`), info)
+	assert.NoError(t, err)
+	assert.Equal(t, ConnectTypeWiredUser, info.ConnectType)
+	assert.Equal(t, WifiMode11bea, info.WifiMode)
+	assert.Equal(t, RadioId6g, info.RadioId)
+	assert.Equal(t, AuthStatusPending, info.AuthStatus)
+	assert.Equal(t, Activity(100), info.Activity)
+	assert.Equal(t, RateLimitModeCustom, info.RateLimit.Mode)
+
+	encoded, err := json.Marshal(info)
+	assert.NoError(t, err)
+	roundTripped := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(encoded, &roundTripped))
+	assert.Equal(t, float64(2), roundTripped["connectType"])
+	assert.Equal(t, float64(9), roundTripped["wifiMode"])
+	assert.Equal(t, float64(3), roundTripped["radioId"])
+}
+
+func TestClientEnums_UnmarshalAcceptsNames(t *testing.T) {
+	var mode WifiMode
+	assert.NoError(t, json.Unmarshal([]byte(`"802.11ac"`), &mode))
+	assert.Equal(t, WifiMode11ac, mode)
+
+	var radio RadioId
+	assert.NoError(t, json.Unmarshal([]byte(`"5GHz-2"`), &radio))
+	assert.Equal(t, RadioId5g2, radio)
+	assert.NoError(t, json.Unmarshal([]byte(`"3"`), &radio))
+	assert.Equal(t, RadioId6g, radio)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`"7GHz"`), &radio), `unknown enum value "7GHz"`)
+	assert.EqualError(t, json.Unmarshal([]byte(`true`), &radio), "invalid enum value true")
+}
@@ -0,0 +1,298 @@
+package omada
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type ExportFormat int
+
+const (
+	ExportCSV ExportFormat = iota
+	// One JSON object per line, holding only the selected columns
+	ExportJSONLines
+	ExportMarkdown
+)
+
+// DefaultClientExportColumns is used when no columns are given
+var DefaultClientExportColumns = []string{"name", "mac", "ip", "ssid", "apName", "rssi", "vid"}
+
+// Columns are named after the ClientInfo JSON fields, with nested fields joined by dots, e.g. "ipSetting.ip" or
+// "rateLimit.customRateLimit.downLimit"
+var clientExportFields = flattenExportFields(reflect.TypeOf(ClientInfo{}), "", nil)
+
+// ClientExportColumns lists every column that can be exported, in ClientInfo field order
+func ClientExportColumns() []string {
+	columns := make([]string, 0, len(clientExportFields))
+	for _, field := range clientExportFields {
+		columns = append(columns, field.name)
+	}
+	return columns
+}
+
+type exportField struct {
+	name  string
+	index []int
+	// Only meaningful for wireless clients, left empty for wired ones rather than showing e.g. radio 0 as 2.4GHz
+	wirelessOnly bool
+}
+
+var wirelessOnlyExportFields = map[string]bool{
+	"ssid": true, "signalLevel": true, "signalRank": true, "wifiMode": true, "apName": true, "apMac": true,
+	"radioId": true, "channel": true, "powerSave": true, "rssi": true, "snr": true, "support5g2": true,
+	"multiLink": true,
+}
+
+func flattenExportFields(t reflect.Type, prefix string, index []int) []exportField {
+	var fields []exportField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		name = prefix + name
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct && !isExportScalar(field.Type) {
+			fields = append(fields, flattenExportFields(field.Type, name+".", fieldIndex)...)
+			continue
+		}
+		fields = append(fields, exportField{name: name, index: fieldIndex, wirelessOnly: wirelessOnlyExportFields[name]})
+	}
+	return fields
+}
+
+func isExportScalar(t reflect.Type) bool {
+	return t.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) ||
+		t.Implements(reflect.TypeOf((*fmt.Stringer)(nil)).Elem())
+}
+
+// ClientExporter writes clients out a batch at a time, so a long client list can be streamed without holding it
+// all in memory. CSV and Markdown render values as text (enums by name, empty MACs blank), JSON Lines keeps the
+// values as the API returns them. Wireless-only columns are left empty (null in JSON Lines) for wired clients.
+// Call Flush when done.
+type ClientExporter struct {
+	format        ExportFormat
+	fields        []exportField
+	writer        *bufio.Writer
+	csv           *csv.Writer
+	headerWritten bool
+}
+
+func NewClientExporter(w io.Writer, format ExportFormat, columns []string) (*ClientExporter, error) {
+	if len(columns) == 0 {
+		columns = DefaultClientExportColumns
+	}
+	fields := make([]exportField, 0, len(columns))
+	for _, column := range columns {
+		field, ok := findExportField(column)
+		if !ok {
+			return nil, fmt.Errorf("unknown client export column %q", column)
+		}
+		fields = append(fields, field)
+	}
+
+	exporter := &ClientExporter{format: format, fields: fields, writer: bufio.NewWriter(w)}
+	switch format {
+	case ExportCSV:
+		exporter.csv = csv.NewWriter(exporter.writer)
+	case ExportJSONLines, ExportMarkdown:
+	default:
+		return nil, fmt.Errorf("unknown export format %d", format)
+	}
+	return exporter, nil
+}
+
+func findExportField(column string) (exportField, bool) {
+	for _, field := range clientExportFields {
+		if field.name == column {
+			return field, true
+		}
+	}
+	return exportField{}, false
+}
+
+func (e *ClientExporter) Write(clients ...ClientInfo) error {
+	if !e.headerWritten {
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+	for _, client := range clients {
+		if err := e.writeClient(client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered output, including the header when no clients were written
+func (e *ClientExporter) Flush() error {
+	if err := e.Write(); err != nil {
+		return err
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return e.writer.Flush()
+}
+
+func (e *ClientExporter) writeHeader() error {
+	names := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		names[i] = field.name
+	}
+	switch e.format {
+	case ExportCSV:
+		return e.csv.Write(names)
+	case ExportMarkdown:
+		separators := make([]string, len(names))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		_, err := fmt.Fprintf(e.writer, "| %s |\n| %s |\n", strings.Join(names, " | "), strings.Join(separators, " | "))
+		return err
+	}
+	return nil
+}
+
+func (e *ClientExporter) writeClient(client ClientInfo) error {
+	value := reflect.ValueOf(client)
+	text := func(field exportField) string {
+		if field.wirelessOnly && !client.Wireless {
+			return ""
+		}
+		return exportText(value.FieldByIndex(field.index))
+	}
+	switch e.format {
+	case ExportCSV:
+		record := make([]string, len(e.fields))
+		for i, field := range e.fields {
+			record[i] = csvCell(text(field))
+		}
+		return e.csv.Write(record)
+	case ExportMarkdown:
+		cells := make([]string, len(e.fields))
+		for i, field := range e.fields {
+			cells[i] = markdownCellReplacer.Replace(text(field))
+		}
+		_, err := fmt.Fprintf(e.writer, "| %s |\n", strings.Join(cells, " | "))
+		return err
+	case ExportJSONLines:
+		// Built by hand so the keys come out in column order
+		var line strings.Builder
+		line.WriteByte('{')
+		for i, field := range e.fields {
+			encoded := []byte("null")
+			if !field.wirelessOnly || client.Wireless {
+				var err error
+				encoded, err = json.Marshal(value.FieldByIndex(field.index).Interface())
+				if err != nil {
+					return fmt.Errorf("could not encode column %s: %w", field.name, err)
+				}
+			}
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			line.WriteString(strconv.Quote(field.name))
+			line.WriteByte(':')
+			line.Write(encoded)
+		}
+		line.WriteString("}\n")
+		_, err := e.writer.WriteString(line.String())
+		return err
+	}
+	return nil
+}
+
+// csvCell stops spreadsheets treating text such as a client name of "=HYPERLINK(...)" as a formula. Numbers, like
+// a negative RSSI, are left alone.
+func csvCell(text string) string {
+	if text == "" || !strings.ContainsAny(text[:1], "=+-@") {
+		return text
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return text
+	}
+	return "'" + text
+}
+
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+func exportText(value reflect.Value) string {
+	switch v := value.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	case fmt.Stringer:
+		return v.String()
+	}
+	switch value.Kind() {
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Struct && !isExportScalar(value.Type().Elem()) {
+			encoded, err := json.Marshal(value.Interface())
+			if err != nil || value.Len() == 0 {
+				return ""
+			}
+			return string(encoded)
+		}
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = exportText(value.Index(i))
+		}
+		return strings.Join(items, ";")
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
+
+// ExportClients writes clients to w in one go
+func ExportClients(w io.Writer, format ExportFormat, columns []string, clients []ClientInfo) error {
+	exporter, err := NewClientExporter(w, format, columns)
+	if err != nil {
+		return err
+	}
+	if err := exporter.Write(clients...); err != nil {
+		return err
+	}
+	return exporter.Flush()
+}
+
+// ExportClients streams the site's client list to w page by page
+func (c *OmadaClient) ExportClients(w io.Writer, siteId string, query ClientListQuery, format ExportFormat, columns []string) error {
+	exporter, err := NewClientExporter(w, format, columns)
+	if err != nil {
+		return err
+	}
+	written := 0
+	for page := 1; ; page++ {
+		response, err := c.GetClientListWithQuery(siteId, page, query)
+		if err != nil {
+			return err
+		}
+		if err := response.Err(); err != nil {
+			return err
+		}
+		if err := exporter.Write(response.Result.Data...); err != nil {
+			return err
+		}
+		written += len(response.Result.Data)
+		if len(response.Result.Data) == 0 || int64(written) >= response.Result.TotalRows {
+			return exporter.Flush()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package omada
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var exportTestClients = []ClientInfo{
+	testClient("11:11:11:11:11:11", ClientInfo{
+		Name: "Phone, Alice's", IP: "10.0.0.11", Wireless: true, SSID: "Home", APName: "Lounge", RSSI: -52, VID: 10,
+		RadioId: RadioId5g, IPv6List: []string{"fe80::1", "2001:db8::1"},
+		IpSetting: ClientIpSetting{UseFixedAddr: true, NetId: "net-1", IP: "10.0.0.11"},
+		RateLimit: ClientRateLimit{Mode: RateLimitModeCustom, CustomRateLimit: CustomRateLimit{DownLimit: 2048, DownLimitEnable: true}},
+	}),
+	testClient("22:22:22:22:22:22", ClientInfo{Name: "Printer | Office", IP: "10.0.0.22", VID: 20}),
+}
+
+func TestClientExportColumns_FlattensNestedFields(t *testing.T) {
+	columns := ClientExportColumns()
+	assert.Contains(t, columns, "mac")
+	assert.Contains(t, columns, "ipSetting.ip")
+	assert.Contains(t, columns, "rateLimit.customRateLimit.downLimit")
+	assert.Contains(t, columns, "clientLockToApSetting.aps")
+	assert.NotContains(t, columns, "ipSetting")
+	for _, column := range DefaultClientExportColumns {
+		assert.Contains(t, columns, column)
+	}
+}
+
+func TestExportClients_WritesCSV(t *testing.T) {
+	var out bytes.Buffer
+	err := ExportClients(&out, ExportCSV, []string{"name", "mac", "apMac", "radioId", "ipv6List", "ipSetting.useFixedAddr", "rateLimit.customRateLimit.downLimit"}, exportTestClients)
+	assert.NoError(t, err)
+	assert.Equal(t, "name,mac,apMac,radioId,ipv6List,ipSetting.useFixedAddr,rateLimit.customRateLimit.downLimit\n"+
+		"\"Phone, Alice's\",11-11-11-11-11-11,,5GHz,fe80::1;2001:db8::1,true,2048\n"+
+		"Printer | Office,22-22-22-22-22-22,,,,false,0\n", out.String())
+}
+
+func TestExportClients_WritesJSONLinesInColumnOrder(t *testing.T) {
+	var out bytes.Buffer
+	err := ExportClients(&out, ExportJSONLines, []string{"vid", "name", "ipSetting.netId", "radioId"}, exportTestClients)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"vid":10,"name":"Phone, Alice's","ipSetting.netId":"net-1","radioId":1}`+"\n"+
+		`{"vid":20,"name":"Printer | Office","ipSetting.netId":"","radioId":null}`+"\n", out.String())
+}
+
+func TestExportClients_WritesMarkdownWithDefaultColumns(t *testing.T) {
+	var out bytes.Buffer
+	err := ExportClients(&out, ExportMarkdown, nil, exportTestClients)
+	assert.NoError(t, err)
+	assert.Equal(t, "| name | mac | ip | ssid | apName | rssi | vid |\n"+
+		"| --- | --- | --- | --- | --- | --- | --- |\n"+
+		"| Phone, Alice's | 11-11-11-11-11-11 | 10.0.0.11 | Home | Lounge | -52 | 10 |\n"+
+		"| Printer \\| Office | 22-22-22-22-22-22 | 10.0.0.22 |  |  |  | 20 |\n", out.String())
+}
+
+func TestExportClients_EscapesCSVFormulas(t *testing.T) {
+	var out bytes.Buffer
+	err := ExportClients(&out, ExportCSV, []string{"name", "hostName", "rssi"}, []ClientInfo{
+		{Name: "=HYPERLINK(\"http://example.com\")", HostName: "@evil", Wireless: true, RSSI: -60},
+		{Name: "+1 phone", HostName: "-rf"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "name,hostName,rssi\n"+
+		"\"'=HYPERLINK(\"\"http://example.com\"\")\",'@evil,-60\n"+
+		"'+1 phone,'-rf,\n", out.String())
+}
+
+func TestExportClients_WritesTheHeaderForAnEmptyList(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, ExportClients(&out, ExportCSV, []string{"name", "mac"}, nil))
+	assert.Equal(t, "name,mac\n", out.String())
+}
+
+func TestNewClientExporter_RejectsUnknownColumnsAndFormats(t *testing.T) {
+	_, err := NewClientExporter(&bytes.Buffer{}, ExportCSV, []string{"name", "nope"})
+	assert.EqualError(t, err, `unknown client export column "nope"`)
+	_, err = NewClientExporter(&bytes.Buffer{}, ExportFormat(42), nil)
+	assert.EqualError(t, err, "unknown export format 42")
+}
+
+func TestOmadaClient_ExportClients_StreamsEveryPage(t *testing.T) {
+	clients := exportTestClients
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/clients", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("pageSize"))
+		var page int
+		_, err := fmt.Sscan(r.URL.Query().Get("page"), &page)
+		assert.NoError(t, err)
+		encoded, err := json.Marshal(clients[page-1 : page])
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(fmt.Sprintf(`{"errorCode": 0, "result": {"totalRows": 2, "data": %s}}`, encoded)))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.PageSize = 1
+	var out bytes.Buffer
+	err := c.ExportClients(&out, "me-site", ClientListQuery{}, ExportCSV, []string{"mac", "vid"})
+	assert.NoError(t, err)
+	assert.Equal(t, "mac,vid\n11-11-11-11-11-11,10\n22-22-22-22-22-22,20\n", out.String())
+}
@@ -0,0 +1,134 @@
+package omada
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// endpointSet is the ordered list of base URLs for a controller and which one is currently in use. The active
+// endpoint is sticky, it only changes when a request to it fails with a connection error or 5xx.
+type endpointSet struct {
+	mu      sync.Mutex
+	urls    []string
+	current int
+}
+
+func newEndpointSet(baseUrls ...string) *endpointSet {
+	urls := make([]string, len(baseUrls))
+	for i, baseUrl := range baseUrls {
+		urls[i] = strings.TrimSuffix(baseUrl, "/")
+	}
+	return &endpointSet{urls: urls}
+}
+
+// NewClientWithFailover creates a client for a controller reachable at several base URLs, in order of preference.
+// Requests go to the first URL until it fails, then fail over to the next.
+func NewClientWithFailover(baseUrls []string, omadaCId, clientId, clientSecret string, disableCertVerification bool) (*OmadaClient, error) {
+	if len(baseUrls) == 0 {
+		return nil, fmt.Errorf("at least one base url is required")
+	}
+	c := NewClient(baseUrls[0], omadaCId, clientId, clientSecret, disableCertVerification)
+	c.SetFailoverBaseUrls(baseUrls[1:]...)
+	return c, nil
+}
+
+// SetFailoverBaseUrls sets the base URLs to fail over to, in order, when the primary base URL is unavailable.
+// The client goes back to using the primary.
+func (c *OmadaClient) SetFailoverBaseUrls(baseUrls ...string) {
+	c.endpoints.reset(baseUrls)
+	c.accessTokenCtx.clearAccessToken()
+}
+
+// reset keeps the primary and replaces the rest. It updates the set in place, requests in flight hold on to it.
+func (e *endpointSet) reset(failoverUrls []string) {
+	updated := newEndpointSet(failoverUrls...)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.urls = append([]string{e.urls[0]}, updated.urls...)
+	e.current = 0
+}
+
+// ActiveBaseUrl is the base URL requests are currently being sent to
+func (c *OmadaClient) ActiveBaseUrl() string {
+	c.endpoints.mu.Lock()
+	defer c.endpoints.mu.Unlock()
+	return c.endpoints.urls[c.endpoints.current]
+}
+
+func (c *OmadaClient) internalHttpDoWithFailover(request *http.Request) ([]byte, error) {
+	c.endpoints.mu.Lock()
+	attempts := len(c.endpoints.urls)
+	c.endpoints.mu.Unlock()
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		baseUrl := c.ActiveBaseUrl()
+		err = c.endpoints.rebaseRequest(request, baseUrl)
+		if err != nil {
+			return nil, err
+		}
+		var allBytes []byte
+		allBytes, err = c.internalHttpDoWithAuthContext(request, 1)
+		if err == nil || !shouldFailover(err) || attempts == 1 {
+			return allBytes, err
+		}
+		if c.endpoints.failover(baseUrl) {
+			// Tokens are issued per controller, so authenticate again against the new endpoint
+			c.accessTokenCtx.clearAccessToken()
+		}
+	}
+	return nil, err
+}
+
+// failover moves to the endpoint after the failed one, unless another request has already moved on from it
+func (e *endpointSet) failover(failed string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.urls[e.current] != failed {
+		return false
+	}
+	e.current = (e.current + 1) % len(e.urls)
+	return true
+}
+
+// rebaseRequest points a request that was built against any of the endpoints at baseUrl instead
+func (e *endpointSet) rebaseRequest(request *http.Request, baseUrl string) error {
+	current := request.URL.String()
+	// Take the longest match, one base URL may be a prefix of another (http://10.0.0.1 and http://10.0.0.10)
+	e.mu.Lock()
+	matched := ""
+	for _, candidate := range e.urls {
+		if strings.HasPrefix(current, candidate) && len(candidate) > len(matched) {
+			matched = candidate
+		}
+	}
+	e.mu.Unlock()
+	if matched == "" || matched == baseUrl {
+		return nil
+	}
+	rebased, err := url.Parse(baseUrl + strings.TrimPrefix(current, matched))
+	if err != nil {
+		return err
+	}
+	request.URL = rebased
+	request.Host = rebased.Host
+	return nil
+}
+
+func shouldFailover(err error) bool {
+	var statusError *UnexpectedStatusError
+	if errors.As(err, &statusError) {
+		return statusError.StatusCode >= 500
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	// Connection refused, DNS, TLS and timeout errors from the transport all come back as a *url.Error
+	var urlError *url.Error
+	return errors.As(err, &urlError)
+}
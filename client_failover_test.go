@@ -0,0 +1,166 @@
+package omada
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func mockControllerWithScenarioStatus(t *testing.T, status int, tokenRequests *int32) *httptest.Server {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(tokenRequests, 1)
+		mockValidTokenResponse(t, w, r)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/scenarios", func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": ["Hotel"]}`))
+		assert.NoError(t, err)
+	})
+	return httptest.NewServer(mockMux)
+}
+
+func TestOmadaClient_Failover_MovesToTheNextEndpointOnA5xx(t *testing.T) {
+	var primaryTokens, secondaryTokens int32
+	primary := mockControllerWithScenarioStatus(t, http.StatusServiceUnavailable, &primaryTokens)
+	defer primary.Close()
+	secondary := mockControllerWithScenarioStatus(t, http.StatusOK, &secondaryTokens)
+	defer secondary.Close()
+
+	c, err := NewClientWithFailover([]string{primary.URL, secondary.URL}, "my-cid", "my-client-id", "my-client-secret", true)
+	assert.NoError(t, err)
+	assert.Equal(t, primary.URL, c.ActiveBaseUrl())
+
+	scenarios, err := c.GetScenarioList()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hotel"}, scenarios.Result)
+	assert.Equal(t, secondary.URL, c.ActiveBaseUrl())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&primaryTokens))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&secondaryTokens))
+
+	// Stays on the secondary for subsequent requests
+	_, err = c.GetScenarioList()
+	assert.NoError(t, err)
+	assert.Equal(t, secondary.URL, c.ActiveBaseUrl())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&secondaryTokens))
+}
+
+func TestOmadaClient_Failover_MovesToTheNextEndpointOnConnectionErrors(t *testing.T) {
+	var secondaryTokens int32
+	primary := httptest.NewServer(http.NotFoundHandler())
+	primary.Close()
+	secondary := mockControllerWithScenarioStatus(t, http.StatusOK, &secondaryTokens)
+	defer secondary.Close()
+
+	c := NewClient(primary.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.SetFailoverBaseUrls(secondary.URL)
+
+	scenarios, err := c.GetScenarioList()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hotel"}, scenarios.Result)
+	assert.Equal(t, secondary.URL, c.ActiveBaseUrl())
+}
+
+func TestOmadaClient_Failover_DoesNotFailOverOnClientErrors(t *testing.T) {
+	var primaryTokens, secondaryTokens int32
+	primary := mockControllerWithScenarioStatus(t, http.StatusForbidden, &primaryTokens)
+	defer primary.Close()
+	secondary := mockControllerWithScenarioStatus(t, http.StatusOK, &secondaryTokens)
+	defer secondary.Close()
+
+	c, err := NewClientWithFailover([]string{primary.URL, secondary.URL}, "my-cid", "my-client-id", "my-client-secret", true)
+	assert.NoError(t, err)
+
+	_, err = c.GetScenarioList()
+
+	assert.EqualError(t, err, "unexpected response error: 403 403 Forbidden")
+	assert.Equal(t, primary.URL, c.ActiveBaseUrl())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&secondaryTokens))
+}
+
+func TestOmadaClient_Failover_ReturnsTheLastErrorWhenEveryEndpointIsDown(t *testing.T) {
+	var primaryTokens, secondaryTokens int32
+	primary := mockControllerWithScenarioStatus(t, http.StatusBadGateway, &primaryTokens)
+	defer primary.Close()
+	secondary := mockControllerWithScenarioStatus(t, http.StatusServiceUnavailable, &secondaryTokens)
+	defer secondary.Close()
+
+	c, err := NewClientWithFailover([]string{primary.URL, secondary.URL}, "my-cid", "my-client-id", "my-client-secret", true)
+	assert.NoError(t, err)
+
+	_, err = c.GetScenarioList()
+
+	assert.EqualError(t, err, "unexpected response error: 503 503 Service Unavailable")
+	assert.Equal(t, primary.URL, c.ActiveBaseUrl())
+}
+
+func TestNewClientWithFailover_RequiresABaseUrl(t *testing.T) {
+	_, err := NewClientWithFailover(nil, "my-cid", "my-client-id", "my-client-secret", true)
+	assert.EqualError(t, err, "at least one base url is required")
+}
+
+func TestOmadaClient_Failover_MovesToTheNextEndpointWhenTheTokenEndpointIsDown(t *testing.T) {
+	var primaryScenarios, secondaryTokens int32
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/scenarios", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryScenarios, 1)
+	})
+	primary := httptest.NewServer(mockMux)
+	defer primary.Close()
+	secondary := mockControllerWithScenarioStatus(t, http.StatusOK, &secondaryTokens)
+	defer secondary.Close()
+
+	c, err := NewClientWithFailover([]string{primary.URL, secondary.URL}, "my-cid", "my-client-id", "my-client-secret", true)
+	assert.NoError(t, err)
+
+	scenarios, err := c.GetScenarioList()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hotel"}, scenarios.Result)
+	assert.Equal(t, secondary.URL, c.ActiveBaseUrl())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&primaryScenarios))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&secondaryTokens))
+}
+
+func TestEndpointSet_RebaseRequest_HandlesBaseUrlsThatPrefixEachOther(t *testing.T) {
+	endpoints := newEndpointSet("http://10.0.0.1", "http://10.0.0.10")
+
+	request, err := http.NewRequest("GET", "http://10.0.0.10/openapi/v1/my-cid/scenarios", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, endpoints.rebaseRequest(request, "http://10.0.0.1"))
+	assert.Equal(t, "http://10.0.0.1/openapi/v1/my-cid/scenarios", request.URL.String())
+	assert.Equal(t, "10.0.0.1", request.Host)
+
+	assert.NoError(t, endpoints.rebaseRequest(request, "http://10.0.0.10"))
+	assert.Equal(t, "http://10.0.0.10/openapi/v1/my-cid/scenarios", request.URL.String())
+
+	assert.NoError(t, endpoints.rebaseRequest(request, "http://10.0.0.10"))
+	assert.Equal(t, "http://10.0.0.10/openapi/v1/my-cid/scenarios", request.URL.String())
+}
+
+func TestOmadaClient_GetToken_ReturnsATypedStatusError(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	_, err := c.GetToken()
+
+	var statusError *UnexpectedStatusError
+	assert.True(t, errors.As(err, &statusError))
+	assert.Equal(t, http.StatusBadGateway, statusError.StatusCode)
+}
@@ -0,0 +1,237 @@
+package omada
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type InventoryDimension int
+
+const (
+	InventoryByOS InventoryDimension = iota
+	InventoryByVendor
+	InventoryByDeviceType
+	InventoryByCategory
+)
+
+var inventoryDimensions = []InventoryDimension{InventoryByOS, InventoryByVendor, InventoryByDeviceType, InventoryByCategory}
+
+func (d InventoryDimension) String() string {
+	switch d {
+	case InventoryByOS:
+		return "os"
+	case InventoryByVendor:
+		return "vendor"
+	case InventoryByDeviceType:
+		return "device type"
+	case InventoryByCategory:
+		return "category"
+	}
+	return fmt.Sprintf("InventoryDimension(%d)", int(d))
+}
+
+// UnknownInventoryValue counts devices the controller couldn't identify
+const UnknownInventoryValue = "Unknown"
+
+type SiteInventory struct {
+	SiteId string
+	Total  int
+	Counts map[InventoryDimension]map[string]int
+}
+
+type InventoryCount struct {
+	Value string
+	Count int
+}
+
+// SummariseInventory counts the clients by OS, vendor, device type and category
+func SummariseInventory(siteId string, clients []ClientInfo) SiteInventory {
+	inventory := newSiteInventory(siteId)
+	for _, client := range clients {
+		inventory.add(client.OsName, client.Vendor, client.DeviceType, client.DeviceCategory)
+	}
+	return inventory
+}
+
+// SummariseKnownClientInventory counts every device in the site's history rather than just the connected ones,
+// which suits a periodic review better
+func SummariseKnownClientInventory(siteId string, knownClients []KnownClient) SiteInventory {
+	inventory := newSiteInventory(siteId)
+	for _, client := range knownClients {
+		inventory.add(client.OsName, client.Vendor, client.DeviceType, client.DeviceCategory)
+	}
+	return inventory
+}
+
+func newSiteInventory(siteId string) SiteInventory {
+	inventory := SiteInventory{SiteId: siteId, Counts: map[InventoryDimension]map[string]int{}}
+	for _, dimension := range inventoryDimensions {
+		inventory.Counts[dimension] = map[string]int{}
+	}
+	return inventory
+}
+
+func (s *SiteInventory) add(osName, vendor, deviceType, category string) {
+	s.Total++
+	values := map[InventoryDimension]string{
+		InventoryByOS:         osName,
+		InventoryByVendor:     vendor,
+		InventoryByDeviceType: deviceType,
+		InventoryByCategory:   category,
+	}
+	for dimension, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			value = UnknownInventoryValue
+		}
+		s.Counts[dimension][value]++
+	}
+}
+
+// Top returns up to n values of the dimension, most common first. All of them if n is negative.
+func (s SiteInventory) Top(dimension InventoryDimension, n int) []InventoryCount {
+	counts := make([]InventoryCount, 0, len(s.Counts[dimension]))
+	for value, count := range s.Counts[dimension] {
+		counts = append(counts, InventoryCount{Value: value, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	if n >= 0 && n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// GetSiteInventory summarises the site's known client history
+func (c *OmadaClient) GetSiteInventory(siteId string, query KnownClientQuery) (SiteInventory, error) {
+	knownClients, err := c.GetAllKnownClients(siteId, query)
+	if err != nil {
+		return SiteInventory{}, err
+	}
+	return SummariseKnownClientInventory(siteId, knownClients), nil
+}
+
+type InventoryTrend struct {
+	Dimension InventoryDimension
+	Value     string
+	Previous  int
+	Current   int
+}
+
+func (t InventoryTrend) Change() int {
+	return t.Current - t.Previous
+}
+
+// CompareInventory lists how every value changed between two summaries of the same site, largest changes first.
+// Values that didn't change are left out.
+func CompareInventory(previous, current SiteInventory) []InventoryTrend {
+	var trends []InventoryTrend
+	for _, dimension := range inventoryDimensions {
+		values := map[string]bool{}
+		for value := range previous.Counts[dimension] {
+			values[value] = true
+		}
+		for value := range current.Counts[dimension] {
+			values[value] = true
+		}
+		for value := range values {
+			trend := InventoryTrend{
+				Dimension: dimension,
+				Value:     value,
+				Previous:  previous.Counts[dimension][value],
+				Current:   current.Counts[dimension][value],
+			}
+			if trend.Change() != 0 {
+				trends = append(trends, trend)
+			}
+		}
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		a, b := trends[i].Change(), trends[j].Change()
+		if a < 0 {
+			a = -a
+		}
+		if b < 0 {
+			b = -b
+		}
+		if a != b {
+			return a > b
+		}
+		if trends[i].Dimension != trends[j].Dimension {
+			return trends[i].Dimension < trends[j].Dimension
+		}
+		return trends[i].Value < trends[j].Value
+	})
+	return trends
+}
+
+// CategoryTracker remembers the device categories seen on each site so new kinds of device stand out
+type CategoryTracker struct {
+	mu    sync.Mutex
+	known map[string]map[string]bool
+}
+
+func NewCategoryTracker() *CategoryTracker {
+	return &CategoryTracker{known: map[string]map[string]bool{}}
+}
+
+// Seed records categories as already seen, e.g. ones saved from a previous review
+func (t *CategoryTracker) Seed(siteId string, categories ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.siteCategories(siteId, categories)
+}
+
+// Observe returns the categories in the inventory that haven't been seen on its site before, in name order. The
+// first inventory for a site that wasn't seeded is taken as the baseline and reports nothing.
+func (t *CategoryTracker) Observe(inventory SiteInventory) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	known, seen := t.known[inventory.SiteId]
+	if !seen {
+		categories := make([]string, 0, len(inventory.Counts[InventoryByCategory]))
+		for category := range inventory.Counts[InventoryByCategory] {
+			categories = append(categories, category)
+		}
+		t.siteCategories(inventory.SiteId, categories)
+		return nil
+	}
+	var added []string
+	for category := range inventory.Counts[InventoryByCategory] {
+		if !known[category] {
+			known[category] = true
+			added = append(added, category)
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
+// KnownCategories returns the categories seen on the site in name order, for saving and seeding later
+func (t *CategoryTracker) KnownCategories(siteId string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var categories []string
+	for category := range t.known[siteId] {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func (t *CategoryTracker) siteCategories(siteId string, categories []string) {
+	known, ok := t.known[siteId]
+	if !ok {
+		known = map[string]bool{}
+		t.known[siteId] = known
+	}
+	for _, category := range categories {
+		known[category] = true
+	}
+}
@@ -0,0 +1,85 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSummariseInventory_CountsEachDimension(t *testing.T) {
+	inventory := SummariseInventory("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{OsName: "iOS", Vendor: "Apple, Inc.", DeviceType: "iPhone", DeviceCategory: "Phone"}),
+		testClient("22:22:22:22:22:22", ClientInfo{OsName: "iOS", Vendor: "Apple, Inc.", DeviceType: "iPad", DeviceCategory: "Tablet"}),
+		testClient("33:33:33:33:33:33", ClientInfo{OsName: "Android", Vendor: "Samsung", DeviceType: "Galaxy", DeviceCategory: "Phone"}),
+		testClient("44:44:44:44:44:44", ClientInfo{Vendor: " "}),
+	})
+
+	assert.Equal(t, "site-a", inventory.SiteId)
+	assert.Equal(t, 4, inventory.Total)
+	assert.Equal(t, map[string]int{"iOS": 2, "Android": 1, UnknownInventoryValue: 1}, inventory.Counts[InventoryByOS])
+	assert.Equal(t, map[string]int{"Apple, Inc.": 2, "Samsung": 1, UnknownInventoryValue: 1}, inventory.Counts[InventoryByVendor])
+	assert.Equal(t, []InventoryCount{{Value: "Phone", Count: 2}, {Value: "Tablet", Count: 1}}, inventory.Top(InventoryByCategory, 2))
+	assert.Len(t, inventory.Top(InventoryByDeviceType, -1), 4)
+}
+
+func TestCompareInventory_ReportsLargestChangesFirst(t *testing.T) {
+	previous := SummariseInventory("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{OsName: "iOS", Vendor: "Apple, Inc.", DeviceType: "iPhone", DeviceCategory: "Phone"}),
+		testClient("22:22:22:22:22:22", ClientInfo{OsName: "Windows", Vendor: "Dell Inc.", DeviceType: "PC", DeviceCategory: "Computer"}),
+	})
+	current := SummariseInventory("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{OsName: "iOS", Vendor: "Apple, Inc.", DeviceType: "iPhone", DeviceCategory: "Phone"}),
+		testClient("22:22:22:22:22:22", ClientInfo{OsName: "Android", Vendor: "Samsung", DeviceType: "Galaxy", DeviceCategory: "Phone"}),
+		testClient("33:33:33:33:33:33", ClientInfo{OsName: "Android", Vendor: "Samsung", DeviceType: "Galaxy", DeviceCategory: "Phone"}),
+	})
+
+	trends := CompareInventory(previous, current)
+	assert.Equal(t, InventoryTrend{Dimension: InventoryByOS, Value: "Android", Previous: 0, Current: 2}, trends[0])
+	assert.Equal(t, 2, trends[0].Change())
+	for _, trend := range trends {
+		assert.NotEqual(t, "iOS", trend.Value)
+	}
+	assert.Contains(t, trends, InventoryTrend{Dimension: InventoryByCategory, Value: "Computer", Previous: 1, Current: 0})
+	assert.Contains(t, trends, InventoryTrend{Dimension: InventoryByCategory, Value: "Phone", Previous: 1, Current: 3})
+}
+
+func TestCategoryTracker_DetectsNewCategories(t *testing.T) {
+	tracker := NewCategoryTracker()
+	baseline := SummariseInventory("site-a", []ClientInfo{testClient("11:11:11:11:11:11", ClientInfo{OsName: "iOS", Vendor: "Apple, Inc.", DeviceType: "iPhone", DeviceCategory: "Phone"})})
+	assert.Empty(t, tracker.Observe(baseline))
+
+	later := SummariseInventory("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{OsName: "iOS", Vendor: "Apple, Inc.", DeviceType: "iPhone", DeviceCategory: "Phone"}),
+		testClient("22:22:22:22:22:22", ClientInfo{Vendor: "Espressif Inc.", DeviceCategory: "Smart Plug"}),
+		testClient("33:33:33:33:33:33", ClientInfo{DeviceCategory: "Camera"}),
+	})
+	assert.Equal(t, []string{"Camera", "Smart Plug"}, tracker.Observe(later))
+	assert.Empty(t, tracker.Observe(later))
+	assert.Equal(t, []string{"Camera", "Phone", "Smart Plug"}, tracker.KnownCategories("site-a"))
+
+	// A seeded site has no baseline poll
+	tracker.Seed("site-b", "Phone")
+	assert.Equal(t, []string{"Camera", "Smart Plug"}, tracker.Observe(SiteInventory{SiteId: "site-b", Counts: later.Counts}))
+}
+
+func TestOmadaClient_GetSiteInventory_SummarisesKnownClients(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/insight/clients", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"errorCode": 0, "result": {"totalRows": 2, "data": [
+			{"mac": "11-11-11-11-11-11", "osName": "iOS", "deviceCategory": "Phone"},
+			{"mac": "22-22-22-22-22-22", "osName": "iOS", "vendor": "Apple, Inc."}
+		]}}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	inventory, err := c.GetSiteInventory("me-site", KnownClientQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inventory.Total)
+	assert.Equal(t, map[string]int{"iOS": 2}, inventory.Counts[InventoryByOS])
+	assert.Equal(t, map[string]int{"Phone": 1, UnknownInventoryValue: 1}, inventory.Counts[InventoryByCategory])
+}
@@ -0,0 +1,137 @@
+package omada
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// KnownClientQuery narrows down the known client history. Start and End filter on when the client was last seen,
+// either may be left zero.
+type KnownClientQuery struct {
+	Start     time.Time
+	End       time.Time
+	SearchKey string
+	Wireless  *bool
+	Guest     *bool
+	Blocked   *bool
+}
+
+func (q KnownClientQuery) encode() string {
+	params := url.Values{}
+	if !q.Start.IsZero() {
+		params.Set("filters.timeStart", strconv.FormatInt(q.Start.UnixMilli(), 10))
+	}
+	if !q.End.IsZero() {
+		params.Set("filters.timeEnd", strconv.FormatInt(q.End.UnixMilli(), 10))
+	}
+	if q.SearchKey != "" {
+		params.Set("searchKey", q.SearchKey)
+	}
+	if q.Wireless != nil {
+		params.Set("filters.wireless", strconv.FormatBool(*q.Wireless))
+	}
+	if q.Guest != nil {
+		params.Set("filters.guest", strconv.FormatBool(*q.Guest))
+	}
+	if q.Blocked != nil {
+		params.Set("filters.block", strconv.FormatBool(*q.Blocked))
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "&" + params.Encode()
+}
+
+// GetKnownClientList pages through every client the controller has seen on the site, connected or not
+func (c *OmadaClient) GetKnownClientList(siteId string, page int, query KnownClientQuery) (*GetKnownClientListResponse, error) {
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/insight/clients?page=%d&pageSize=%d%s", c.ActiveBaseUrl(), c.omadaCId, siteId, page, c.PageSize, query.encode())
+	request, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	knownClientList := &GetKnownClientListResponse{}
+	err = c.httpDoCached(CacheEndpointKnownClientList, request, knownClientList)
+	if err != nil {
+		return nil, err
+	}
+
+	return knownClientList, nil
+}
+
+// GetAllKnownClients follows GetKnownClientList through every page
+func (c *OmadaClient) GetAllKnownClients(siteId string, query KnownClientQuery) ([]KnownClient, error) {
+	var knownClients []KnownClient
+	for page := 1; ; page++ {
+		response, err := c.GetKnownClientList(siteId, page, query)
+		if err != nil {
+			return nil, err
+		}
+		if err := response.Err(); err != nil {
+			return nil, err
+		}
+		knownClients = append(knownClients, response.Result.Data...)
+		if len(response.Result.Data) == 0 || int64(len(knownClients)) >= response.Result.TotalRows {
+			return knownClients, nil
+		}
+	}
+}
+
+// ForgetKnownClient removes the client from the site's history. A currently connected client will reappear.
+func (c *OmadaClient) ForgetKnownClient(siteId string, clientMac MAC) error {
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/insight/clients/%s", c.ActiveBaseUrl(), c.omadaCId, siteId, clientMac)
+	request, err := http.NewRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+
+	response := &EnvelopeResponse{}
+	err = c.httpDoMutating(request, response, CacheEndpointKnownClientList)
+	if err != nil {
+		return err
+	}
+	return response.Err()
+}
+
+type GetKnownClientListResponse struct {
+	EnvelopeResponse
+	Result struct {
+		TotalRows   int64         `json:"totalRows"`
+		CurrentPage int32         `json:"currentPage"`
+		CurrentSize int32         `json:"currentSize"`
+		Data        []KnownClient `json:"data"`
+	} `json:"result"`
+}
+
+type KnownClient struct {
+	MAC            MAC    `json:"mac"`
+	Name           string `json:"name"`
+	Vendor         string `json:"vendor"`
+	DeviceType     string `json:"deviceType"`
+	DeviceCategory string `json:"deviceCategory"`
+	OsName         string `json:"osName"`
+	Wireless       bool   `json:"wireless"`
+	Guest          bool   `json:"guest"`
+	Blocked        bool   `json:"block"`
+	Manager        bool   `json:"manager"`
+	Download       int64  `json:"download"`
+	Upload         int64  `json:"upload"`
+	// Total connected time in seconds
+	Duration int64 `json:"duration"`
+	// Milliseconds since the epoch
+	LastSeen int64 `json:"lastSeen"`
+}
+
+func (k KnownClient) ConnectedDuration() time.Duration {
+	return time.Duration(k.Duration) * time.Second
+}
+
+func (k KnownClient) LastSeenTime() time.Time {
+	if k.LastSeen == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(k.LastSeen).UTC()
+}
@@ -0,0 +1,134 @@
+package omada
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOmadaClient_GetKnownClientList_ReturnsAValidKnownClientList(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/insight/clients", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "AccessToken=my-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+		assert.Equal(t, "100", r.URL.Query().Get("pageSize"))
+		assert.Equal(t, "1698710400000", r.URL.Query().Get("filters.timeStart"))
+		assert.Equal(t, "1698796800000", r.URL.Query().Get("filters.timeEnd"))
+		assert.Equal(t, "true", r.URL.Query().Get("filters.guest"))
+		assert.Equal(t, "", r.URL.Query().Get("filters.wireless"))
+		_, err := w.Write([]byte(`{
+		  "errorCode": 0,
+		  "msg": "Success.",
+		  "result": {
+			"totalRows": 1,
+			"currentPage": 1,
+			"currentSize": 100,
+			"data": [
+			  {
+				"mac": "AA-BB-CC-DD-EE-FF",
+				"name": "Old Phone",
+				"vendor": "My Vendor",
+				"deviceType": "phone",
+				"deviceCategory": "mobile",
+				"osName": "Some OS",
+				"wireless": true,
+				"guest": true,
+				"block": false,
+				"manager": false,
+				"download": 123456789,
+				"upload": 987654,
+				"duration": 7200,
+				"lastSeen": 1698744232047
+			  }
+			]
+		  }
+		}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	guest := true
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	knownClients, err := c.GetKnownClientList("me-site", 1, KnownClientQuery{
+		Start: time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+		Guest: &guest,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, knownClients.ErrorCode, 0)
+	assert.Equal(t, knownClients.Result.TotalRows, int64(1))
+	assert.Equal(t, knownClients.Result.Data[0].MAC, MustParseMAC("AA-BB-CC-DD-EE-FF"))
+	assert.Equal(t, knownClients.Result.Data[0].Name, "Old Phone")
+	assert.Equal(t, knownClients.Result.Data[0].Vendor, "My Vendor")
+	assert.Equal(t, knownClients.Result.Data[0].DeviceType, "phone")
+	assert.Equal(t, knownClients.Result.Data[0].DeviceCategory, "mobile")
+	assert.Equal(t, knownClients.Result.Data[0].OsName, "Some OS")
+	assert.Equal(t, knownClients.Result.Data[0].Wireless, true)
+	assert.Equal(t, knownClients.Result.Data[0].Guest, true)
+	assert.Equal(t, knownClients.Result.Data[0].Blocked, false)
+	assert.Equal(t, knownClients.Result.Data[0].Manager, false)
+	assert.Equal(t, knownClients.Result.Data[0].Download, int64(123456789))
+	assert.Equal(t, knownClients.Result.Data[0].Upload, int64(987654))
+	assert.Equal(t, knownClients.Result.Data[0].ConnectedDuration(), 2*time.Hour)
+	assert.Equal(t, knownClients.Result.Data[0].LastSeenTime(), time.Date(2023, 10, 31, 9, 23, 52, 47000000, time.UTC))
+}
+
+func TestOmadaClient_GetAllKnownClients_FollowsEveryPage(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/insight/clients", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("pageSize"))
+		page := r.URL.Query().Get("page")
+		var data string
+		switch page {
+		case "1":
+			data = `{"mac": "11-11-11-11-11-11"}, {"mac": "22-22-22-22-22-22"}`
+		case "2":
+			data = `{"mac": "33-33-33-33-33-33"}`
+		default:
+			assert.Fail(t, "unexpected page "+page)
+		}
+		_, err := w.Write([]byte(fmt.Sprintf(`{"errorCode": 0, "result": {"totalRows": 3, "data": [%s]}}`, data)))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	c.PageSize = 2
+	knownClients, err := c.GetAllKnownClients("me-site", KnownClientQuery{})
+
+	assert.NoError(t, err)
+	assert.Len(t, knownClients, 3)
+	assert.Equal(t, MustParseMAC("33-33-33-33-33-33"), knownClients[2].MAC)
+}
+
+func TestOmadaClient_ForgetKnownClient_DeletesTheClient(t *testing.T) {
+	deleted := false
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/insight/clients/AA-BB-CC-DD-EE-FF", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		deleted = true
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success."}`))
+		assert.NoError(t, err)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/me-site/insight/clients/11-11-11-11-11-11", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"errorCode": -1005, "msg": "Operation forbidden."}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+
+	assert.NoError(t, c.ForgetKnownClient("me-site", MustParseMAC("aa:bb:cc:dd:ee:ff")))
+	assert.True(t, deleted)
+	assert.ErrorIs(t, c.ForgetKnownClient("me-site", MustParseMAC("11:11:11:11:11:11")), ErrPermissionDenied)
+}
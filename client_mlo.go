@@ -0,0 +1,127 @@
+package omada
+
+import (
+	"bytes"
+	"sort"
+)
+
+// IsMLO reports whether the client is connected over Wi-Fi 7 multi-link operation
+func (c ClientInfo) IsMLO() bool {
+	return len(c.MultiLink) > 0
+}
+
+type MLOSummary struct {
+	MAC    MAC
+	Name   string
+	APMac  MAC
+	APName string
+	Links  []ClientLink
+	// Links passing traffic right now
+	ActiveLinks int
+	// Sums over every link, in the units the controller reports per link
+	RxRate      int
+	TxRate      int
+	TrafficDown int
+	TrafficUp   int
+	Activity    Activity
+	// Links whose RSSI is poor by the SignalReportConfig thresholds
+	WeakLinks []ClientLink
+}
+
+// Bands lists the radios the client has links on, in radio order
+func (s MLOSummary) Bands() []RadioId {
+	seen := map[RadioId]bool{}
+	var bands []RadioId
+	for _, link := range s.Links {
+		if !seen[link.RadioId] {
+			seen[link.RadioId] = true
+			bands = append(bands, link.RadioId)
+		}
+	}
+	sort.Slice(bands, func(i, j int) bool { return bands[i] < bands[j] })
+	return bands
+}
+
+// SummariseMLOClient returns false for clients that aren't using MLO
+func SummariseMLOClient(client ClientInfo, config SignalReportConfig) (MLOSummary, bool) {
+	if !client.IsMLO() {
+		return MLOSummary{}, false
+	}
+	config = config.withDefaults()
+	summary := MLOSummary{MAC: client.MAC, Name: client.Name, APMac: client.APMac, APName: client.APName, Links: client.MultiLink}
+	for _, link := range client.MultiLink {
+		if link.Activity > 0 {
+			summary.ActiveLinks++
+		}
+		summary.RxRate += link.RxRate
+		summary.TxRate += link.TxRate
+		summary.TrafficDown += link.TrafficDown
+		summary.TrafficUp += link.TrafficUp
+		summary.Activity += link.Activity
+		if link.RSSI != 0 && config.bucket(link.RSSI) == SignalPoor {
+			summary.WeakLinks = append(summary.WeakLinks, link)
+		}
+	}
+	return summary, true
+}
+
+// SummariseMLOClients skips clients that aren't using MLO
+func SummariseMLOClients(clients []ClientInfo, config SignalReportConfig) []MLOSummary {
+	var summaries []MLOSummary
+	for _, client := range clients {
+		if summary, ok := SummariseMLOClient(client, config); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+type MLOBandUsage struct {
+	APMac   MAC
+	APName  string
+	RadioId RadioId
+	// MLO clients with a link on this radio
+	Clients     int
+	TrafficDown int
+	TrafficUp   int
+	Activity    Activity
+}
+
+// MLOUsage reports how much MLO traffic each AP radio is carrying, ordered by AP and radio
+func MLOUsage(clients []ClientInfo) []MLOBandUsage {
+	type key struct {
+		apMac   MAC
+		radioId RadioId
+	}
+	usage := map[key]*MLOBandUsage{}
+	for _, client := range clients {
+		counted := map[RadioId]bool{}
+		for _, link := range client.MultiLink {
+			k := key{apMac: client.APMac, radioId: link.RadioId}
+			band, ok := usage[k]
+			if !ok {
+				band = &MLOBandUsage{APMac: client.APMac, APName: client.APName, RadioId: link.RadioId}
+				usage[k] = band
+			}
+			if !counted[link.RadioId] {
+				counted[link.RadioId] = true
+				band.Clients++
+			}
+			band.TrafficDown += link.TrafficDown
+			band.TrafficUp += link.TrafficUp
+			band.Activity += link.Activity
+		}
+	}
+
+	bands := make([]MLOBandUsage, 0, len(usage))
+	for _, band := range usage {
+		bands = append(bands, *band)
+	}
+	sort.Slice(bands, func(i, j int) bool {
+		if bands[i].APMac != bands[j].APMac {
+			return bytes.Compare(bands[i].APMac[:], bands[j].APMac[:]) < 0
+		}
+		return bands[i].RadioId < bands[j].RadioId
+	})
+	return bands
+}
@@ -0,0 +1,66 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSummariseMLOClient(t *testing.T) {
+	client := testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP", MultiLink: []ClientLink{
+		{RadioId: RadioId6g, RxRate: 2000, TxRate: 1500, RSSI: -60, TrafficDown: 100, TrafficUp: 10, Activity: 500},
+		{RadioId: RadioId5g, RxRate: 1000, TxRate: 800, RSSI: -80, TrafficDown: 50, TrafficUp: 5},
+	}})
+
+	summary, ok := SummariseMLOClient(client, SignalReportConfig{})
+	assert.True(t, ok)
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:01"), summary.APMac)
+	assert.Len(t, summary.Links, 2)
+	assert.Equal(t, 1, summary.ActiveLinks)
+	assert.Equal(t, 3000, summary.RxRate)
+	assert.Equal(t, 2300, summary.TxRate)
+	assert.Equal(t, 150, summary.TrafficDown)
+	assert.Equal(t, 15, summary.TrafficUp)
+	assert.Equal(t, Activity(500), summary.Activity)
+	assert.Equal(t, []RadioId{RadioId5g, RadioId6g}, summary.Bands())
+	assert.Len(t, summary.WeakLinks, 1)
+	assert.Equal(t, RadioId5g, summary.WeakLinks[0].RadioId)
+
+	summary, _ = SummariseMLOClient(client, SignalReportConfig{FairRSSI: -85})
+	assert.Empty(t, summary.WeakLinks)
+
+	_, ok = SummariseMLOClient(ClientInfo{Wireless: true}, SignalReportConfig{})
+	assert.False(t, ok)
+}
+
+func TestSummariseMLOClients_SkipsNonMLOClients(t *testing.T) {
+	summaries := SummariseMLOClients([]ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP", MultiLink: []ClientLink{{RadioId: RadioId6g}, {RadioId: RadioId5g}}}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true}),
+	}, SignalReportConfig{})
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, MustParseMAC("11:11:11:11:11:11"), summaries[0].MAC)
+}
+
+func TestMLOUsage_ReportsTrafficPerAPRadio(t *testing.T) {
+	usage := MLOUsage([]ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP", MultiLink: []ClientLink{
+			{RadioId: RadioId6g, TrafficDown: 100, TrafficUp: 10, Activity: 5},
+			{RadioId: RadioId5g, TrafficDown: 50, TrafficUp: 5, Activity: 1},
+		}}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP", MultiLink: []ClientLink{
+			{RadioId: RadioId6g, TrafficDown: 200, TrafficUp: 20, Activity: 7},
+		}}),
+		testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP", MultiLink: []ClientLink{
+			{RadioId: RadioId5g, TrafficDown: 1},
+			{RadioId: RadioId5g2, TrafficDown: 2},
+		}}),
+		testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId2g}),
+	})
+
+	assert.Equal(t, []MLOBandUsage{
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP", RadioId: RadioId5g, Clients: 1, TrafficDown: 1},
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP", RadioId: RadioId5g2, Clients: 1, TrafficDown: 2},
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP", RadioId: RadioId5g, Clients: 1, TrafficDown: 50, TrafficUp: 5, Activity: 1},
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP", RadioId: RadioId6g, Clients: 2, TrafficDown: 300, TrafficUp: 30, Activity: 12},
+	}, usage)
+}
@@ -0,0 +1,174 @@
+package omada
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// SwitchPort identifies where a wired client is plugged in. LagID is non-zero when the port belongs to a LAG, in
+// which case Port is zero as the client may be reached over any of the LAG's member ports.
+type SwitchPort struct {
+	SwitchMac MAC
+	Port      int
+	LagID     int
+}
+
+func (p SwitchPort) String() string {
+	if p.LagID > 0 {
+		return fmt.Sprintf("%s LAG %d", p.SwitchMac, p.LagID)
+	}
+	return fmt.Sprintf("%s port %d", p.SwitchMac, p.Port)
+}
+
+// WiredSwitchPort returns the switch port of a wired client, false for wireless clients and clients that aren't
+// behind an Omada switch
+func WiredSwitchPort(client ClientInfo) (SwitchPort, bool) {
+	if client.Wireless || client.SwitchMac.IsZero() {
+		return SwitchPort{}, false
+	}
+	if client.LagID != 0 {
+		return SwitchPort{SwitchMac: client.SwitchMac, LagID: client.LagID}, true
+	}
+	return SwitchPort{SwitchMac: client.SwitchMac, Port: client.Port}, true
+}
+
+type ClientPortLocation struct {
+	SwitchPort
+	SiteId       string
+	SwitchName   string
+	StandardPort string
+	Client       ClientInfo
+}
+
+// LocateClientPorts finds the switch ports of the wired clients matching target, which may be a host or client name
+// (case insensitive), a MAC address or an IP address. Names are tried first, so a host name that happens to look
+// like a MAC, e.g. "aabbccddeeff", finds that host.
+func LocateClientPorts(siteId string, clients []ClientInfo, target string) []ClientPortLocation {
+	return locateClientPorts([]siteClients{{siteId: siteId, clients: clients}}, target)
+}
+
+type siteClients struct {
+	siteId  string
+	clients []ClientInfo
+}
+
+func locateClientPorts(sites []siteClients, target string) []ClientPortLocation {
+	for _, matches := range clientMatchers(target) {
+		var locations []ClientPortLocation
+		for _, site := range sites {
+			for _, client := range site.clients {
+				port, ok := WiredSwitchPort(client)
+				if !ok || !matches(client) {
+					continue
+				}
+				locations = append(locations, ClientPortLocation{
+					SwitchPort:   port,
+					SiteId:       site.siteId,
+					SwitchName:   client.SwitchName,
+					StandardPort: client.StandardPort,
+					Client:       client,
+				})
+			}
+		}
+		if len(locations) > 0 {
+			return locations
+		}
+	}
+	return nil
+}
+
+// clientMatchers returns the ways target can match a client, to be tried in order until one matches something
+func clientMatchers(target string) []func(ClientInfo) bool {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil
+	}
+	matchers := []func(ClientInfo) bool{func(client ClientInfo) bool {
+		return strings.EqualFold(client.HostName, target) || strings.EqualFold(client.Name, target)
+	}}
+	if mac, err := ParseMAC(target); err == nil {
+		matchers = append(matchers, func(client ClientInfo) bool { return client.MAC == mac })
+	}
+	if addr, err := netip.ParseAddr(target); err == nil {
+		matchers = append(matchers, func(client ClientInfo) bool {
+			for _, clientAddr := range client.Addrs() {
+				if clientAddr == addr {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	return matchers
+}
+
+// LocateClient searches the given sites, or every site if none are given, for the switch port target is plugged
+// into. Returns ErrClientNotFound if no wired client matches.
+func (c *OmadaClient) LocateClient(target string, siteIds ...string) ([]ClientPortLocation, error) {
+	if len(siteIds) == 0 {
+		sites, err := c.GetAllSites()
+		if err != nil {
+			return nil, err
+		}
+		for _, site := range sites {
+			siteIds = append(siteIds, site.SiteId)
+		}
+	}
+
+	wired := false
+	sites := make([]siteClients, 0, len(siteIds))
+	for _, siteId := range siteIds {
+		clients, err := c.GetAllClients(siteId, ClientListQuery{Wireless: &wired})
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, siteClients{siteId: siteId, clients: clients})
+	}
+	locations := locateClientPorts(sites, target)
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("%w: no wired client matches %q", ErrClientNotFound, target)
+	}
+	return locations, nil
+}
+
+// GroupClientsBySwitchPort inverts the lookup, listing the wired clients on each switch port
+func GroupClientsBySwitchPort(clients []ClientInfo) map[SwitchPort][]ClientInfo {
+	ports := map[SwitchPort][]ClientInfo{}
+	for _, client := range clients {
+		if port, ok := WiredSwitchPort(client); ok {
+			ports[port] = append(ports[port], client)
+		}
+	}
+	return ports
+}
+
+// SortedSwitchPorts returns the keys of a GroupClientsBySwitchPort result ordered by switch, then LAG, then port
+func SortedSwitchPorts(ports map[SwitchPort][]ClientInfo) []SwitchPort {
+	sorted := make([]SwitchPort, 0, len(ports))
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.SwitchMac != b.SwitchMac {
+			return a.SwitchMac.String() < b.SwitchMac.String()
+		}
+		if a.LagID != b.LagID {
+			return a.LagID < b.LagID
+		}
+		return a.Port < b.Port
+	})
+	return sorted
+}
+
+// GetClientsBySwitchPort lists the site's wired clients grouped by the switch port they're plugged into
+func (c *OmadaClient) GetClientsBySwitchPort(siteId string) (map[SwitchPort][]ClientInfo, error) {
+	wired := false
+	clients, err := c.GetAllClients(siteId, ClientListQuery{Wireless: &wired})
+	if err != nil {
+		return nil, err
+	}
+	return GroupClientsBySwitchPort(clients), nil
+}
@@ -0,0 +1,105 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWiredSwitchPort_OnlyAppliesToClientsBehindASwitch(t *testing.T) {
+	port, ok := WiredSwitchPort(testClient("11:11:11:11:11:11", ClientInfo{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 5}))
+	assert.True(t, ok)
+	assert.Equal(t, "AA-AA-AA-AA-AA-01 port 5", port.String())
+
+	// The reported port is whichever LAG member the client was last seen on
+	port, ok = WiredSwitchPort(testClient("11:11:11:11:11:11", ClientInfo{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 5, LagID: 2}))
+	assert.True(t, ok)
+	assert.Equal(t, SwitchPort{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), LagID: 2}, port)
+	assert.Equal(t, "AA-AA-AA-AA-AA-01 LAG 2", port.String())
+
+	_, ok = WiredSwitchPort(testClient("22:22:22:22:22:22", ClientInfo{}))
+	assert.False(t, ok)
+	_, ok = WiredSwitchPort(ClientInfo{Wireless: true, SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01")})
+	assert.False(t, ok)
+}
+
+func TestLocateClientPorts_MatchesByMacIpOrHostName(t *testing.T) {
+	clients := []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{HostName: "nas", IP: "10.0.0.11", SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SwitchName: "Core", Port: 5}),
+		testClient("22:22:22:22:22:22", ClientInfo{HostName: "printer", IP: "10.0.0.22", SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:02"), Port: 7}),
+		testClient("33:33:33:33:33:33", ClientInfo{HostName: "nas", Wireless: true}),
+	}
+
+	byMac := LocateClientPorts("site-a", clients, "11-11-11-11-11-11")
+	assert.Len(t, byMac, 1)
+	assert.Equal(t, "site-a", byMac[0].SiteId)
+	assert.Equal(t, 5, byMac[0].Port)
+	assert.Equal(t, "Core", byMac[0].SwitchName)
+
+	byIp := LocateClientPorts("site-a", clients, "10.0.0.22")
+	assert.Len(t, byIp, 1)
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:02"), byIp[0].SwitchMac)
+
+	// The wireless client with the same host name has no port
+	byHostName := LocateClientPorts("site-a", clients, "NAS")
+	assert.Len(t, byHostName, 1)
+	assert.Equal(t, MustParseMAC("11:11:11:11:11:11"), byHostName[0].Client.MAC)
+
+	assert.Empty(t, LocateClientPorts("site-a", clients, "10.0.0.99"))
+	assert.Empty(t, LocateClientPorts("site-a", clients, " "))
+}
+
+func TestLocateClientPorts_PrefersHostNamesThatLookLikeMacs(t *testing.T) {
+	clients := []ClientInfo{
+		testClient("aa:bb:cc:dd:ee:ff", ClientInfo{HostName: "printer", SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 1}),
+		testClient("11:11:11:11:11:11", ClientInfo{HostName: "aabbccddeeff", SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 2}),
+	}
+
+	locations := LocateClientPorts("site-a", clients, "AABBCCDDEEFF")
+	assert.Len(t, locations, 1)
+	assert.Equal(t, 2, locations[0].Port)
+
+	// Without a host of that name it's a MAC
+	locations = LocateClientPorts("site-a", clients[:1], "aabbccddeeff")
+	assert.Len(t, locations, 1)
+	assert.Equal(t, 1, locations[0].Port)
+}
+
+func TestOmadaClient_LocateClient_SearchesEverySite(t *testing.T) {
+	controller, server := newMockClientListController(t)
+	defer server.Close()
+	controller.set("site-a", testClient("11:11:11:11:11:11", ClientInfo{HostName: "nas", SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 5}))
+	controller.set("site-b", testClient("22:22:22:22:22:22", ClientInfo{HostName: "printer", SwitchMac: MustParseMAC("bb:bb:bb:bb:bb:01"), Port: 3, LagID: 1}))
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	locations, err := c.LocateClient("printer")
+	assert.NoError(t, err)
+	assert.Len(t, locations, 1)
+	assert.Equal(t, "site-b", locations[0].SiteId)
+	assert.Equal(t, 1, locations[0].LagID)
+	assert.Equal(t, 3, locations[0].Client.Port)
+
+	_, err = c.LocateClient("printer", "site-a")
+	assert.ErrorIs(t, err, ErrClientNotFound)
+}
+
+func TestGroupClientsBySwitchPort_InvertsTheMapping(t *testing.T) {
+	clients := []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:02"), Port: 5}),
+		testClient("22:22:22:22:22:22", ClientInfo{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 7}),
+		testClient("33:33:33:33:33:33", ClientInfo{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 7}),
+		// Seen on different members of the same LAG
+		testClient("44:44:44:44:44:44", ClientInfo{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 1, LagID: 1}),
+		testClient("66:66:66:66:66:66", ClientInfo{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 2, LagID: 1}),
+		testClient("55:55:55:55:55:55", ClientInfo{Wireless: true}),
+	}
+
+	ports := GroupClientsBySwitchPort(clients)
+	assert.Len(t, ports, 3)
+	assert.Equal(t, []string{"22:22:22:22:22:22", "33:33:33:33:33:33"}, clientNames(ports[SwitchPort{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 7}]))
+	assert.Equal(t, []string{"44:44:44:44:44:44", "66:66:66:66:66:66"}, clientNames(ports[SwitchPort{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), LagID: 1}]))
+	assert.Equal(t, []SwitchPort{
+		{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), Port: 7},
+		{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:01"), LagID: 1},
+		{SwitchMac: MustParseMAC("aa:aa:aa:aa:aa:02"), Port: 5},
+	}, SortedSwitchPorts(ports))
+}
@@ -0,0 +1,211 @@
+package omada
+
+import (
+	"bytes"
+	"sort"
+)
+
+type SignalBucket int
+
+const (
+	SignalPoor SignalBucket = iota
+	SignalFair
+	SignalGood
+)
+
+func (b SignalBucket) String() string {
+	switch b {
+	case SignalPoor:
+		return "poor"
+	case SignalFair:
+		return "fair"
+	}
+	return "good"
+}
+
+type SignalReportConfig struct {
+	// RSSI in dBm at or above which a client's signal is good, defaults to -65
+	GoodRSSI int
+	// RSSI in dBm at or above which a client's signal is fair, below it is poor. Defaults to -75.
+	FairRSSI int
+	// Share of an AP's clients that have to be poor for it to be flagged as weak, defaults to 0.5
+	WeakAPPoorShare float64
+	// APs with fewer clients than this aren't flagged, defaults to 3
+	WeakAPMinClients int
+}
+
+func (c SignalReportConfig) withDefaults() SignalReportConfig {
+	if c.GoodRSSI == 0 {
+		c.GoodRSSI = -65
+	}
+	if c.FairRSSI == 0 {
+		c.FairRSSI = -75
+	}
+	if c.WeakAPPoorShare <= 0 {
+		c.WeakAPPoorShare = 0.5
+	}
+	if c.WeakAPMinClients <= 0 {
+		c.WeakAPMinClients = 3
+	}
+	return c
+}
+
+func (c SignalReportConfig) bucket(rssi int) SignalBucket {
+	switch {
+	case rssi >= c.GoodRSSI:
+		return SignalGood
+	case rssi >= c.FairRSSI:
+		return SignalFair
+	}
+	return SignalPoor
+}
+
+type SignalDistribution struct {
+	Min int
+	// The 10th percentile, i.e. how the weakest clients are doing
+	P10    int
+	Median float64
+}
+
+type SignalGroup struct {
+	APMac   MAC
+	SSID    string
+	RadioId RadioId
+}
+
+type SignalQuality struct {
+	SignalGroup
+	APName  string
+	Clients int
+	RSSI    SignalDistribution
+	SNR     SignalDistribution
+	Poor    int
+	Fair    int
+	Good    int
+	// Share of clients in power save mode, from 0 to 1
+	PowerSaveShare float64
+}
+
+func (q SignalQuality) PoorShare() float64 {
+	if q.Clients == 0 {
+		return 0
+	}
+	return float64(q.Poor) / float64(q.Clients)
+}
+
+type WeakAP struct {
+	APMac      MAC
+	APName     string
+	Clients    int
+	Poor       int
+	MedianRSSI float64
+}
+
+type SignalReport struct {
+	// Ordered by AP, SSID and radio
+	Groups []SignalQuality
+	// APs where at least WeakAPPoorShare of the clients have a poor signal, weakest first
+	WeakAPs []WeakAP
+}
+
+// BuildSignalReport summarises the signal of wireless clients by AP, SSID and radio. Clients from several polls can
+// be passed together to find APs that are weak over time rather than at one moment. Clients without an RSSI are
+// skipped.
+func BuildSignalReport(clients []ClientInfo, config SignalReportConfig) SignalReport {
+	config = config.withDefaults()
+
+	groups := map[SignalGroup][]ClientInfo{}
+	aps := map[MAC][]ClientInfo{}
+	for _, client := range clients {
+		if !client.Wireless || client.RSSI == 0 {
+			continue
+		}
+		group := SignalGroup{APMac: client.APMac, SSID: client.SSID, RadioId: client.RadioId}
+		groups[group] = append(groups[group], client)
+		aps[client.APMac] = append(aps[client.APMac], client)
+	}
+
+	var report SignalReport
+	for group, groupClients := range groups {
+		quality := SignalQuality{SignalGroup: group, APName: groupClients[0].APName, Clients: len(groupClients)}
+		rssi := make([]int, len(groupClients))
+		snr := make([]int, len(groupClients))
+		powerSave := 0
+		for i, client := range groupClients {
+			rssi[i], snr[i] = client.RSSI, client.SNR
+			switch config.bucket(client.RSSI) {
+			case SignalPoor:
+				quality.Poor++
+			case SignalFair:
+				quality.Fair++
+			case SignalGood:
+				quality.Good++
+			}
+			if client.PowerSave {
+				powerSave++
+			}
+		}
+		quality.RSSI = distribution(rssi)
+		quality.SNR = distribution(snr)
+		quality.PowerSaveShare = float64(powerSave) / float64(len(groupClients))
+		report.Groups = append(report.Groups, quality)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.APMac != b.APMac {
+			return bytes.Compare(a.APMac[:], b.APMac[:]) < 0
+		}
+		if a.SSID != b.SSID {
+			return a.SSID < b.SSID
+		}
+		return a.RadioId < b.RadioId
+	})
+
+	for apMac, apClients := range aps {
+		if len(apClients) < config.WeakAPMinClients {
+			continue
+		}
+		rssi := make([]int, len(apClients))
+		poor := 0
+		for i, client := range apClients {
+			rssi[i] = client.RSSI
+			if config.bucket(client.RSSI) == SignalPoor {
+				poor++
+			}
+		}
+		if float64(poor)/float64(len(apClients)) < config.WeakAPPoorShare {
+			continue
+		}
+		report.WeakAPs = append(report.WeakAPs, WeakAP{
+			APMac:      apMac,
+			APName:     apClients[0].APName,
+			Clients:    len(apClients),
+			Poor:       poor,
+			MedianRSSI: distribution(rssi).Median,
+		})
+	}
+	sort.Slice(report.WeakAPs, func(i, j int) bool {
+		a, b := report.WeakAPs[i], report.WeakAPs[j]
+		if a.MedianRSSI != b.MedianRSSI {
+			return a.MedianRSSI < b.MedianRSSI
+		}
+		return bytes.Compare(a.APMac[:], b.APMac[:]) < 0
+	})
+	return report
+}
+
+// distribution sorts values in place
+func distribution(values []int) SignalDistribution {
+	if len(values) == 0 {
+		return SignalDistribution{}
+	}
+	sort.Ints(values)
+	middle := len(values) / 2
+	median := float64(values[middle])
+	if len(values)%2 == 0 {
+		median = float64(values[middle-1]+values[middle]) / 2
+	}
+	// Nearest rank, so the 10th percentile of fewer than ten values is the minimum
+	rank := (len(values)*10 + 99) / 100
+	return SignalDistribution{Min: values[0], P10: values[rank-1], Median: median}
+}
@@ -0,0 +1,72 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDistribution(t *testing.T) {
+	assert.Equal(t, SignalDistribution{}, distribution(nil))
+	assert.Equal(t, SignalDistribution{Min: -70, P10: -70, Median: -70}, distribution([]int{-70}))
+	assert.Equal(t, SignalDistribution{Min: -80, P10: -80, Median: -60}, distribution([]int{-50, -80, -70, -50}))
+
+	values := make([]int, 20)
+	for i := range values {
+		values[i] = -i
+	}
+	assert.Equal(t, SignalDistribution{Min: -19, P10: -18, Median: -9.5}, distribution(values))
+}
+
+func TestBuildSignalReport_GroupsByAPSSIDAndRadio(t *testing.T) {
+	clients := []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Home", RadioId: RadioId5g, RSSI: -55, SNR: 40}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Home", RadioId: RadioId5g, RSSI: -70, SNR: 25, PowerSave: true}),
+		testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Home", RadioId: RadioId5g, RSSI: -80, SNR: 12, PowerSave: true}),
+		testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Home", RadioId: RadioId2g, RSSI: -60, SNR: 30}),
+		// No RSSI reported
+		testClient("55:55:55:55:55:55", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Home", RadioId: RadioId2g}),
+		testClient("66:66:66:66:66:66", ClientInfo{}),
+	}
+
+	report := BuildSignalReport(clients, SignalReportConfig{})
+	assert.Len(t, report.Groups, 2)
+	assert.Equal(t, RadioId2g, report.Groups[0].RadioId)
+	assert.Equal(t, 1, report.Groups[0].Clients)
+
+	fiveGHz := report.Groups[1]
+	assert.Equal(t, SignalGroup{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), SSID: "Home", RadioId: RadioId5g}, fiveGHz.SignalGroup)
+	assert.Equal(t, "AP 01", fiveGHz.APName)
+	assert.Equal(t, 3, fiveGHz.Clients)
+	assert.Equal(t, SignalDistribution{Min: -80, P10: -80, Median: -70}, fiveGHz.RSSI)
+	assert.Equal(t, SignalDistribution{Min: 12, P10: 12, Median: 25}, fiveGHz.SNR)
+	assert.Equal(t, 1, fiveGHz.Good)
+	assert.Equal(t, 1, fiveGHz.Fair)
+	assert.Equal(t, 1, fiveGHz.Poor)
+	assert.InDelta(t, 2.0/3, fiveGHz.PowerSaveShare, 0.001)
+	assert.InDelta(t, 1.0/3, fiveGHz.PoorShare(), 0.001)
+	assert.Empty(t, report.WeakAPs)
+}
+
+func TestBuildSignalReport_FlagsWeakAPs(t *testing.T) {
+	clients := []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Home", RadioId: RadioId5g, RSSI: -78, SNR: 10}),
+		testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Home", RadioId: RadioId2g, RSSI: -82, SNR: 8}),
+		testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", SSID: "Guest", RadioId: RadioId5g, RSSI: -60, SNR: 30}),
+		testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP 02", SSID: "Home", RadioId: RadioId5g, RSSI: -85, SNR: 5}),
+		testClient("55:55:55:55:55:55", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP 02", SSID: "Home", RadioId: RadioId5g, RSSI: -88, SNR: 3}),
+		testClient("66:66:66:66:66:66", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP 02", SSID: "Home", RadioId: RadioId5g, RSSI: -76, SNR: 9}),
+		// Too few clients to judge
+		testClient("77:77:77:77:77:77", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:03"), APName: "AP 03", SSID: "Home", RadioId: RadioId5g, RSSI: -90, SNR: 2}),
+	}
+
+	report := BuildSignalReport(clients, SignalReportConfig{})
+	assert.Equal(t, []WeakAP{
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), APName: "AP 02", Clients: 3, Poor: 3, MedianRSSI: -85},
+		{APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), APName: "AP 01", Clients: 3, Poor: 2, MedianRSSI: -78},
+	}, report.WeakAPs)
+
+	report = BuildSignalReport(clients, SignalReportConfig{WeakAPPoorShare: 0.9, WeakAPMinClients: 1})
+	assert.Len(t, report.WeakAPs, 2)
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:03"), report.WeakAPs[0].APMac)
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:02"), report.WeakAPs[1].APMac)
+}
@@ -6,11 +6,11 @@ import (
 )
 
 func (c *OmadaClient) GetSiteList(page int) (*GetSiteListResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/sites?pageSize=%d&page=%d", c.baseUrl, c.omadaCId, c.PageSize, page)
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites?pageSize=%d&page=%d", c.ActiveBaseUrl(), c.omadaCId, c.PageSize, page)
 	request, err := http.NewRequest("GET", path, nil)
 
 	siteList := &GetSiteListResponse{}
-	err = c.httpDoWrapped(request, siteList)
+	err = c.httpDoCached(CacheEndpointSiteList, request, siteList)
 	if err != nil {
 		return nil, err
 	}
@@ -18,12 +18,30 @@ func (c *OmadaClient) GetSiteList(page int) (*GetSiteListResponse, error) {
 	return siteList, nil
 }
 
+// GetAllSites follows GetSiteList through every page
+func (c *OmadaClient) GetAllSites() ([]SiteEntity, error) {
+	var sites []SiteEntity
+	for page := 1; ; page++ {
+		response, err := c.GetSiteList(page)
+		if err != nil {
+			return nil, err
+		}
+		if err := response.Err(); err != nil {
+			return nil, err
+		}
+		sites = append(sites, response.Result.Data...)
+		if len(response.Result.Data) == 0 || len(sites) >= response.Result.TotalRows {
+			return sites, nil
+		}
+	}
+}
+
 func (c *OmadaClient) GetSiteInfo(site string) (*GetSiteInfoResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s", c.baseUrl, c.omadaCId, site)
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s", c.ActiveBaseUrl(), c.omadaCId, site)
 	request, err := http.NewRequest("GET", path, nil)
 
 	siteInfo := &GetSiteInfoResponse{}
-	err = c.httpDoWrapped(request, siteInfo)
+	err = c.httpDoCached(CacheEndpointSiteInfo, request, siteInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -59,11 +77,11 @@ type SiteEntity struct {
 }
 
 func (c *OmadaClient) GetScenarioList() (*GetScenarioListResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/scenarios", c.baseUrl, c.omadaCId)
+	path := fmt.Sprintf("%s/openapi/v1/%s/scenarios", c.ActiveBaseUrl(), c.omadaCId)
 	request, err := http.NewRequest("GET", path, nil)
 
 	scenario := &GetScenarioListResponse{}
-	err = c.httpDoWrapped(request, scenario)
+	err = c.httpDoCached(CacheEndpointScenarioList, request, scenario)
 	if err != nil {
 		return nil, err
 	}
@@ -77,11 +95,11 @@ type GetScenarioListResponse struct {
 }
 
 func (c *OmadaClient) GetSiteDeviceAccountSetting(siteId string) (*GetSiteDeviceAccountSettingResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/device-account", c.baseUrl, c.omadaCId, siteId)
+	path := fmt.Sprintf("%s/openapi/v1/%s/sites/%s/device-account", c.ActiveBaseUrl(), c.omadaCId, siteId)
 	request, err := http.NewRequest("GET", path, nil)
 
 	scenario := &GetSiteDeviceAccountSettingResponse{}
-	err = c.httpDoWrapped(request, scenario)
+	err = c.httpDoCached(CacheEndpointSiteDeviceAccount, request, scenario)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,115 @@
+package omada
+
+// ClientHealthThresholds sets where the Poor, Fair and Good health counts are split. The controller doesn't publish
+// the lines it draws for its own counts, so set these to match what it shows where that matters.
+type ClientHealthThresholds struct {
+	// Scores below Fair are Poor
+	Fair int
+	// Scores from Good up are Good, the ones in between Fair
+	Good int
+}
+
+// DefaultClientHealthThresholds is what Add and ComputeClientStat use
+var DefaultClientHealthThresholds = ClientHealthThresholds{Fair: 40, Good: 70}
+
+// ComputeClientStat counts clients into a ClientStat for any subset of them, e.g. one SSID after FilterClients. The
+// totals are counted the way the controller counts them, the health counts depend on DefaultClientHealthThresholds
+// and may not match the controller's.
+func ComputeClientStat(clients []ClientInfo) ClientStat {
+	return ComputeClientStatWithThresholds(clients, DefaultClientHealthThresholds)
+}
+
+func ComputeClientStatWithThresholds(clients []ClientInfo, thresholds ClientHealthThresholds) ClientStat {
+	var stat ClientStat
+	for _, client := range clients {
+		stat.AddWithThresholds(client, thresholds)
+	}
+	return stat
+}
+
+// Add counts client in the totals using DefaultClientHealthThresholds
+func (s *ClientStat) Add(client ClientInfo) {
+	s.AddWithThresholds(client, DefaultClientHealthThresholds)
+}
+
+// AddWithThresholds counts client in the totals. Only wireless clients have a health score, so only they are counted
+// in Poor, Fair, Good and NoData.
+func (s *ClientStat) AddWithThresholds(client ClientInfo, thresholds ClientHealthThresholds) {
+	s.Total++
+	if client.Guest {
+		s.NumGuest++
+	} else {
+		s.NumUser++
+	}
+
+	if !client.Wireless {
+		s.Wired++
+		return
+	}
+	s.Wireless++
+	switch {
+	// The controller sends -1 when it has no data, a ClientInfo built without a score has 0
+	case client.HealthScore <= 0:
+		s.NoData++
+	case client.HealthScore < thresholds.Fair:
+		s.Poor++
+	case client.HealthScore < thresholds.Good:
+		s.Fair++
+	default:
+		s.Good++
+	}
+	if client.Guest {
+		s.NumWirelessGuest++
+	} else {
+		s.NumWirelessUser++
+	}
+	switch client.RadioId {
+	case RadioId2g:
+		s.Num2g++
+		if client.Guest {
+			s.Num2gGuest++
+		} else {
+			s.Num2gUser++
+		}
+	case RadioId5g, RadioId5g2:
+		s.Num5g++
+		if client.Guest {
+			s.Num5gGuest++
+		} else {
+			s.Num5gUser++
+		}
+	case RadioId6g:
+		s.Num6g++
+		if client.Guest {
+			s.Num6gGuest++
+		} else {
+			s.Num6gUser++
+		}
+	}
+}
+
+// GroupClientStats computes a ClientStat for each distinct key
+func GroupClientStats[K comparable](clients []ClientInfo, key func(ClientInfo) K) map[K]ClientStat {
+	stats := map[K]ClientStat{}
+	for _, client := range clients {
+		k := key(client)
+		stat := stats[k]
+		stat.Add(client)
+		stats[k] = stat
+	}
+	return stats
+}
+
+// ClientStatsBySSID leaves wired clients out
+func ClientStatsBySSID(clients []ClientInfo) map[string]ClientStat {
+	return GroupClientStats(FilterClients(clients, func(c ClientInfo) bool { return c.Wireless }), func(c ClientInfo) string { return c.SSID })
+}
+
+// ClientStatsByAP leaves wired clients out
+func ClientStatsByAP(clients []ClientInfo) map[MAC]ClientStat {
+	return GroupClientStats(FilterClients(clients, func(c ClientInfo) bool { return c.Wireless }), func(c ClientInfo) MAC { return c.APMac })
+}
+
+func ClientStatsByVLAN(clients []ClientInfo) map[int]ClientStat {
+	return GroupClientStats(clients, func(c ClientInfo) int { return c.VID })
+}
@@ -0,0 +1,82 @@
+package omada
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var statTestClients = []ClientInfo{
+	testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, SSID: "Home", APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId2g, VID: 10, HealthScore: 20}),
+	testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, SSID: "Home", APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g2, VID: 10, HealthScore: 55}),
+	testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, SSID: "Guest", APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), RadioId: RadioId6g, VID: 20, Guest: true, HealthScore: 95}),
+	testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, SSID: "Guest", APMac: MustParseMAC("aa:aa:aa:aa:aa:02"), RadioId: RadioId5g, VID: 20, Guest: true, HealthScore: 70}),
+	testClient("55:55:55:55:55:55", ClientInfo{Wireless: true, SSID: "Home", APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g, VID: 10, HealthScore: -1}),
+	// Wired clients have no health score at all
+	testClient("66:66:66:66:66:66", ClientInfo{VID: 10}),
+}
+
+func TestComputeClientStat_CountsEachBreakdown(t *testing.T) {
+	assert.Equal(t, ClientStat{
+		Total:            6,
+		Wireless:         5,
+		Wired:            1,
+		Num2g:            1,
+		Num5g:            3,
+		Num6g:            1,
+		NumUser:          4,
+		NumGuest:         2,
+		NumWirelessUser:  3,
+		NumWirelessGuest: 2,
+		Num2gUser:        1,
+		Num5gUser:        2,
+		Num6gUser:        0,
+		Num2gGuest:       0,
+		Num5gGuest:       1,
+		Num6gGuest:       1,
+		Poor:             1,
+		Fair:             1,
+		NoData:           1,
+		Good:             2,
+	}, ComputeClientStat(statTestClients))
+	assert.Equal(t, ClientStat{}, ComputeClientStat(nil))
+}
+
+func TestComputeClientStat_CountsAMissingHealthScoreAsNoData(t *testing.T) {
+	var clients []ClientInfo
+	err := json.Unmarshal([]byte(`[
+		{"mac": "11-11-11-11-11-11", "wireless": true},
+		{"mac": "22-22-22-22-22-22", "wireless": true, "healthScore": -1}
+	]`), &clients)
+	assert.NoError(t, err)
+	clients = append(clients, testClient("33:33:33:33:33:33", ClientInfo{Wireless: true}))
+
+	stat := ComputeClientStat(clients)
+	assert.Equal(t, 3, stat.NoData)
+	assert.Equal(t, 0, stat.Poor)
+}
+
+func TestComputeClientStatWithThresholds(t *testing.T) {
+	stat := ComputeClientStatWithThresholds(statTestClients, ClientHealthThresholds{Fair: 60, Good: 90})
+	assert.Equal(t, 2, stat.Poor)
+	assert.Equal(t, 1, stat.Fair)
+	assert.Equal(t, 1, stat.Good)
+	assert.Equal(t, 1, stat.NoData)
+}
+
+func TestClientStatsBySSIDAPAndVLAN(t *testing.T) {
+	bySSID := ClientStatsBySSID(statTestClients)
+	assert.Len(t, bySSID, 2)
+	assert.Equal(t, 3, bySSID["Home"].NumWirelessUser)
+	assert.Equal(t, 2, bySSID["Guest"].NumWirelessGuest)
+
+	byAP := ClientStatsByAP(statTestClients)
+	assert.Len(t, byAP, 2)
+	assert.Equal(t, 1, byAP[MustParseMAC("aa:aa:aa:aa:aa:02")].Num6g)
+
+	byVLAN := ClientStatsByVLAN(statTestClients)
+	assert.Equal(t, 4, byVLAN[10].Total)
+	assert.Equal(t, 1, byVLAN[10].Wired)
+	assert.Equal(t, 1, byVLAN[10].NoData)
+	assert.Equal(t, 2, byVLAN[20].Good)
+}
@@ -2,10 +2,13 @@ package omada
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -98,3 +101,56 @@ func TestNewClient_TokenRefreshingLogic_WillPropagateTheErrorIfItCannotRefresh(t
 type TestTokenRequest struct {
 	OmadacId string `json:"omadacId"`
 }
+
+func TestNewClient_TokenRefreshingLogic_ConcurrentExpiriesShareASingleRefresh(t *testing.T) {
+	const concurrency = 20
+	var tokenRequests int32
+	var expiredArrivals sync.WaitGroup
+	expiredArrivals.Add(concurrency)
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) {
+		issued := atomic.AddInt32(&tokenRequests, 1)
+		_, err := w.Write([]byte(fmt.Sprintf(`{"errorCode": 0, "result": {"accessToken": "token-%d"}}`, issued)))
+		assert.NoError(t, err)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/scenarios", func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if authorization == "AccessToken=token-1" {
+			// Hold every request carrying the first token until they have all arrived, so they all see it expire together
+			expiredArrivals.Done()
+			expiredArrivals.Wait()
+			mockTokenExpiredResponse(t, w, r)
+			return
+		}
+		if authorization != fmt.Sprintf("AccessToken=token-%d", atomic.LoadInt32(&tokenRequests)) {
+			mockTokenExpiredResponse(t, w, r)
+			return
+		}
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": []}`))
+		assert.NoError(t, err)
+	})
+	server := httptest.NewServer(mockMux)
+	defer server.Close()
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	_, _, err := c.accessTokenCtx.initialiseAccessTokenIfNeeded(c)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scenarios, err := c.GetScenarioList()
+			assert.NoError(t, err)
+			if scenarios != nil {
+				assert.Equal(t, 0, scenarios.ErrorCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// One token for the initial acquisition and exactly one more for the expiry
+	assert.Equal(t, int32(2), atomic.LoadInt32(&tokenRequests))
+}
@@ -0,0 +1,74 @@
+package omada
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UptimeDuration converts Uptime, which the controller reports in seconds
+func (c ClientInfo) UptimeDuration() time.Duration {
+	return time.Duration(c.Uptime) * time.Second
+}
+
+// LastSeenTime converts LastSeen, which the controller reports in milliseconds since the epoch. The result is in
+// UTC, use LastSeenIn for the site's local time. Zero if the controller didn't report it.
+func (c ClientInfo) LastSeenTime() time.Time {
+	if c.LastSeen == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(c.LastSeen)).UTC()
+}
+
+// LastSeenIn is LastSeenTime in the time zone configured for site
+func (c ClientInfo) LastSeenIn(site SiteEntity) (time.Time, error) {
+	lastSeen := c.LastSeenTime()
+	if lastSeen.IsZero() {
+		return lastSeen, nil
+	}
+	return site.InSiteTime(lastSeen)
+}
+
+// Matches offset style zones, either bare ("UTC+08:00", "GMT-5") or in the controller UI's display form
+// ("(UTC+08:00) Beijing, Chongqing")
+var utcOffsetZonePattern = regexp.MustCompile(`^\(?(?:UTC|GMT)\s*(?:([+-])(\d{1,2})(?::?(\d{2}))?)?(?:\)|\s|$)`)
+
+// Location resolves the site's TimeZone. IANA names are loaded from the system zone database, offset style names
+// become a fixed zone. Sites without a time zone are treated as UTC.
+func (s SiteEntity) Location() (*time.Location, error) {
+	zone := strings.TrimSpace(s.TimeZone)
+	if zone == "" {
+		return time.UTC, nil
+	}
+	if location, err := time.LoadLocation(zone); err == nil {
+		return location, nil
+	}
+	match := utcOffsetZonePattern.FindStringSubmatch(zone)
+	if match == nil {
+		return nil, fmt.Errorf("unrecognised site time zone %q", s.TimeZone)
+	}
+	if match[1] == "" {
+		return time.UTC, nil
+	}
+	hours, _ := strconv.Atoi(match[2])
+	minutes, _ := strconv.Atoi(match[3])
+	if hours > 14 || minutes > 59 {
+		return nil, fmt.Errorf("unrecognised site time zone %q", s.TimeZone)
+	}
+	offset := hours*3600 + minutes*60
+	if match[1] == "-" {
+		offset = -offset
+	}
+	return time.FixedZone(strings.Trim(match[0], "() "), offset), nil
+}
+
+// InSiteTime converts t, e.g. a controller timestamp, to the site's local time
+func (s SiteEntity) InSiteTime(t time.Time) (time.Time, error) {
+	location, err := s.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(location), nil
+}
@@ -0,0 +1,64 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClientInfo_UptimeDuration(t *testing.T) {
+	client := ClientInfo{Uptime: 291632}
+	assert.Equal(t, 291632*time.Second, client.UptimeDuration())
+}
+
+func TestClientInfo_LastSeenTime(t *testing.T) {
+	client := ClientInfo{LastSeen: 1698744232047}
+	assert.Equal(t, time.Date(2023, 10, 31, 9, 23, 52, 47000000, time.UTC), client.LastSeenTime())
+	assert.True(t, ClientInfo{}.LastSeenTime().IsZero())
+}
+
+func TestClientInfo_LastSeenIn_ConvertsToTheSiteTimeZone(t *testing.T) {
+	client := ClientInfo{LastSeen: 1698744232047}
+
+	local, err := client.LastSeenIn(SiteEntity{TimeZone: "UTC+08:00"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2023-10-31 17:23:52 +0800", local.Format("2006-01-02 15:04:05 -0700"))
+
+	local, err = client.LastSeenIn(SiteEntity{TimeZone: "(UTC-05:30) Somewhere"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2023-10-31 03:53:52 -0530", local.Format("2006-01-02 15:04:05 -0700"))
+
+	_, err = client.LastSeenIn(SiteEntity{TimeZone: "Mars/Olympus_Mons"})
+	assert.EqualError(t, err, `unrecognised site time zone "Mars/Olympus_Mons"`)
+}
+
+func TestSiteEntity_Location(t *testing.T) {
+	for zone, offset := range map[string]int{
+		"":                               0,
+		"UTC":                            0,
+		"GMT":                            0,
+		"UTC+10":                         10 * 3600,
+		"GMT-3":                          -3 * 3600,
+		"UTC+05:45":                      5*3600 + 45*60,
+		"(UTC+08:00) Beijing, Chongqing": 8 * 3600,
+	} {
+		location, err := SiteEntity{TimeZone: zone}.Location()
+		assert.NoError(t, err, zone)
+		_, actual := time.Date(2023, 1, 1, 0, 0, 0, 0, location).Zone()
+		assert.Equal(t, offset, actual, zone)
+	}
+
+	_, err := SiteEntity{TimeZone: "UTC+25:00"}.Location()
+	assert.Error(t, err)
+	_, err = SiteEntity{TimeZone: "UTCish"}.Location()
+	assert.Error(t, err)
+}
+
+func TestSiteEntity_Location_LoadsIANAZones(t *testing.T) {
+	if _, err := time.LoadLocation("Australia/Sydney"); err != nil {
+		t.Skip("no time zone database available")
+	}
+	location, err := SiteEntity{TimeZone: "Australia/Sydney"}.Location()
+	assert.NoError(t, err)
+	assert.Equal(t, "Australia/Sydney", location.String())
+}
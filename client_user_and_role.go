@@ -6,11 +6,11 @@ import (
 )
 
 func (c *OmadaClient) GetRoleList() (*GetRoleListResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/roles", c.baseUrl, c.omadaCId)
+	path := fmt.Sprintf("%s/openapi/v1/%s/roles", c.ActiveBaseUrl(), c.omadaCId)
 	request, err := http.NewRequest("GET", path, nil)
 
 	roleListResponse := &GetRoleListResponse{}
-	err = c.httpDoWrapped(request, roleListResponse)
+	err = c.httpDoCached(CacheEndpointRoleList, request, roleListResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -18,11 +18,11 @@ func (c *OmadaClient) GetRoleList() (*GetRoleListResponse, error) {
 }
 
 func (c *OmadaClient) GetRoleInfo(roleId string) (*GetRoleInfoResponse, error) {
-	path := fmt.Sprintf("%s/openapi/v1/%s/roles/%s", c.baseUrl, c.omadaCId, roleId)
+	path := fmt.Sprintf("%s/openapi/v1/%s/roles/%s", c.ActiveBaseUrl(), c.omadaCId, roleId)
 	request, err := http.NewRequest("GET", path, nil)
 
 	roleInfoResponse := &GetRoleInfoResponse{}
-	err = c.httpDoWrapped(request, roleInfoResponse)
+	err = c.httpDoCached(CacheEndpointRoleInfo, request, roleInfoResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,120 @@
+// Command ouigen downloads the IEEE MA-L registry and writes the assignment lines of it, gzipped, for the omada
+// package to embed. Run it with go generate from the repository root.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const registryUrl = "https://standards-oui.ieee.org/oui/oui.txt"
+
+// Anything smaller isn't the full registry, e.g. an error page
+const minAssignments = 10000
+
+var assignmentPattern = regexp.MustCompile(`^[0-9A-Fa-f]{2}-[0-9A-Fa-f]{2}-[0-9A-Fa-f]{2}\s+\(hex\)\s+\S`)
+
+func main() {
+	url := flag.String("url", registryUrl, "where to download the registry from")
+	in := flag.String("in", "", "read the registry from this file instead of downloading it")
+	out := flag.String("out", "oui.txt.gz", "file to write")
+	from := flag.String("source", "", "where the registry came from, for the header, defaults to -in or -url")
+	min := flag.Int("min", minAssignments, "fail if the registry has fewer assignments than this")
+	flag.Parse()
+
+	registry, err := open(*url, *in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer registry.Close()
+
+	tmp := *out + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(tmp)
+	source := *from
+	if source == "" {
+		source = *url
+		if *in != "" {
+			source = *in
+		}
+	}
+	count, err := writeCompressed(file, registry, source)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if count < *min {
+		log.Fatalf("only found %d assignments, expected at least %d", count, *min)
+	}
+	if err := os.Rename(tmp, *out); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d assignments to %s", count, *out)
+}
+
+func open(url, path string) (io.ReadCloser, error) {
+	if path != "" {
+		return os.Open(path)
+	}
+	client := http.Client{Timeout: 5 * time.Minute}
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("could not download %s: %s", url, response.Status)
+	}
+	return response.Body, nil
+}
+
+func writeCompressed(w io.Writer, registry io.Reader, source string) (int, error) {
+	compressed, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return 0, err
+	}
+	count, err := writeAssignments(compressed, registry, source)
+	if err != nil {
+		return count, err
+	}
+	return count, compressed.Close()
+}
+
+// writeAssignments keeps the "XX-XX-XX (hex) Organization" lines, dropping the addresses and the duplicate base 16
+// lines, which are most of the file
+func writeAssignments(w io.Writer, registry io.Reader, source string) (int, error) {
+	writer := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(writer, "Generated by ouigen from %s\n\n", source); err != nil {
+		return 0, err
+	}
+	count := 0
+	scanner := bufio.NewScanner(registry)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !assignmentPattern.MatchString(line) {
+			continue
+		}
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Flush()
+}
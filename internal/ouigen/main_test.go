@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteCompressed_KeepsOnlyTheAssignments(t *testing.T) {
+	var out bytes.Buffer
+	count, err := writeCompressed(&out, strings.NewReader("OUI/MA-L\t\t\tOrganization\n"+
+		"company_id\t\tOrganization\n\n"+
+		"00-1A-2B   (hex)\t\tAcme Widgets Ltd\n"+
+		"001A2B     (base 16)\t\tAcme Widgets Ltd\n"+
+		"\t\t\t\t1 Example Road\n\n"+
+		"a4-b5-c6   (hex)\t\tExample Corp\n"), registryUrl)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	reader, err := gzip.NewReader(&out)
+	assert.NoError(t, err)
+	text, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "Generated by ouigen from "+registryUrl+"\n\n"+
+		"00-1A-2B   (hex)\t\tAcme Widgets Ltd\n"+
+		"a4-b5-c6   (hex)\t\tExample Corp\n", string(text))
+}
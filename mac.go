@@ -0,0 +1,94 @@
+package omada
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MAC is a 48-bit hardware address. It parses any of the common notations and formats in the upper case,
+// hyphen separated form the controller uses, so it can be compared and used as a map key directly.
+type MAC [6]byte
+
+// OUI is the organisationally unique identifier, the first three bytes of a MAC
+type OUI [3]byte
+
+// ParseMAC accepts colon, hyphen or dot separated addresses as well as bare hex, in any case
+func ParseMAC(s string) (MAC, error) {
+	var mac MAC
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) == 12 {
+		if _, err := hex.Decode(mac[:], []byte(trimmed)); err != nil {
+			return MAC{}, fmt.Errorf("invalid MAC address %q", s)
+		}
+		return mac, nil
+	}
+	hw, err := net.ParseMAC(trimmed)
+	if err != nil || len(hw) != len(mac) {
+		return MAC{}, fmt.Errorf("invalid MAC address %q", s)
+	}
+	copy(mac[:], hw)
+	return mac, nil
+}
+
+func MustParseMAC(s string) MAC {
+	mac, err := ParseMAC(s)
+	if err != nil {
+		panic(err)
+	}
+	return mac
+}
+
+// String formats the MAC the way the controller does, e.g. AA-BB-CC-DD-EE-FF
+func (m MAC) String() string {
+	return fmt.Sprintf("%02X-%02X-%02X-%02X-%02X-%02X", m[0], m[1], m[2], m[3], m[4], m[5])
+}
+
+func (m MAC) IsZero() bool {
+	return m == MAC{}
+}
+
+func (m MAC) OUI() OUI {
+	return OUI{m[0], m[1], m[2]}
+}
+
+// IsLocallyAdministered reports whether the address was assigned locally rather than from a vendor's OUI, which
+// is how phones and laptops randomise their MAC per network
+func (m MAC) IsLocallyAdministered() bool {
+	return m[0]&0x02 != 0
+}
+
+func (m MAC) IsMulticast() bool {
+	return m[0]&0x01 != 0
+}
+
+func (m MAC) HardwareAddr() net.HardwareAddr {
+	return net.HardwareAddr(m[:])
+}
+
+// MarshalText writes the controller format, or an empty string for the zero MAC so that absent addresses
+// (e.g. the AP of a wired client) round trip
+func (m MAC) MarshalText() ([]byte, error) {
+	if m.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(m.String()), nil
+}
+
+func (m *MAC) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*m = MAC{}
+		return nil
+	}
+	parsed, err := ParseMAC(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (o OUI) String() string {
+	return fmt.Sprintf("%02X-%02X-%02X", o[0], o[1], o[2])
+}
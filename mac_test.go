@@ -0,0 +1,70 @@
+package omada
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseMAC_AcceptsCommonNotations(t *testing.T) {
+	for _, notation := range []string{
+		"AA-BB-CC-DD-EE-FF",
+		"aa-bb-cc-dd-ee-ff",
+		"aa:bb:cc:dd:ee:ff",
+		"AA:BB:CC:DD:EE:FF",
+		"aabb.ccdd.eeff",
+		"aabbccddeeff",
+		" AABBCCDDEEFF ",
+	} {
+		mac, err := ParseMAC(notation)
+		assert.NoError(t, err, notation)
+		assert.Equal(t, MAC{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, mac, notation)
+		assert.Equal(t, "AA-BB-CC-DD-EE-FF", mac.String(), notation)
+	}
+}
+
+func TestParseMAC_RejectsInvalidAddresses(t *testing.T) {
+	for _, notation := range []string{"", "aa:bb:cc:dd:ee", "zz:bb:cc:dd:ee:ff", "aabbccddeefg", "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01"} {
+		_, err := ParseMAC(notation)
+		assert.Error(t, err, notation)
+	}
+}
+
+func TestMAC_OUI(t *testing.T) {
+	mac := MustParseMAC("00:1a:2b:3c:4d:5e")
+	assert.Equal(t, OUI{0x00, 0x1a, 0x2b}, mac.OUI())
+	assert.Equal(t, "00-1A-2B", mac.OUI().String())
+}
+
+func TestMAC_AddressBits(t *testing.T) {
+	assert.False(t, MustParseMAC("00:1a:2b:3c:4d:5e").IsLocallyAdministered())
+	assert.True(t, MustParseMAC("da:a1:19:3c:4d:5e").IsLocallyAdministered())
+	assert.True(t, MustParseMAC("52:54:00:12:34:56").IsLocallyAdministered())
+	assert.False(t, MustParseMAC("00:1a:2b:3c:4d:5e").IsMulticast())
+	assert.True(t, MustParseMAC("01:00:5e:00:00:01").IsMulticast())
+}
+
+func TestMAC_JSONRoundTripsInOmadaFormat(t *testing.T) {
+	type payload struct {
+		MAC   MAC `json:"mac"`
+		APMac MAC `json:"apMac"`
+	}
+
+	decoded := &payload{}
+	err := json.Unmarshal([]byte(`{"mac": "aa:bb:cc:dd:ee:ff", "apMac": ""}`), decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, MustParseMAC("AA-BB-CC-DD-EE-FF"), decoded.MAC)
+	assert.True(t, decoded.APMac.IsZero())
+
+	encoded, err := json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"mac": "AA-BB-CC-DD-EE-FF", "apMac": ""}`, string(encoded))
+
+	err = json.Unmarshal([]byte(`{"mac": "not-a-mac"}`), decoded)
+	assert.EqualError(t, err, `invalid MAC address "not-a-mac"`)
+}
+
+func TestMAC_CanBeUsedAsAMapKeyAcrossNotations(t *testing.T) {
+	seen := map[MAC]bool{MustParseMAC("aa:bb:cc:dd:ee:ff"): true}
+	assert.True(t, seen[MustParseMAC("AA-BB-CC-DD-EE-FF")])
+}
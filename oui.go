@@ -0,0 +1,199 @@
+package omada
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// The IEEE MA-L registry, cut down to its assignment lines. go generate downloads the current one.
+//
+//go:generate go run ./internal/ouigen -out oui.txt.gz
+//go:embed oui.txt.gz
+var embeddedOUIs []byte
+
+// OUIDatabase maps OUIs to vendor names. It starts out with the embedded registry, which is decompressed on first
+// use, and can be replaced with a newer one using Load.
+type OUIDatabase struct {
+	mu      sync.RWMutex
+	once    sync.Once
+	vendors map[OUI]string
+}
+
+// DefaultOUIDatabase is used by EnrichClientVendors when no database is given
+var DefaultOUIDatabase = NewOUIDatabase()
+
+func NewOUIDatabase() *OUIDatabase {
+	return &OUIDatabase{}
+}
+
+func (d *OUIDatabase) loadEmbedded() {
+	d.once.Do(func() {
+		reader, err := gzip.NewReader(bytes.NewReader(embeddedOUIs))
+		if err != nil {
+			panic(fmt.Sprintf("embedded OUI database: %v", err))
+		}
+		vendors, err := parseOUIs(reader)
+		if err != nil {
+			panic(fmt.Sprintf("embedded OUI database: %v", err))
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.vendors = vendors
+	})
+}
+
+// Load replaces the database with the registry read from r, either the IEEE oui.txt or oui.csv format
+func (d *OUIDatabase) Load(r io.Reader) error {
+	vendors, err := parseOUIs(r)
+	if err != nil {
+		return err
+	}
+	if len(vendors) == 0 {
+		return errors.New("no OUIs found")
+	}
+	// Nothing left to decompress
+	d.once.Do(func() {})
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.vendors = vendors
+	return nil
+}
+
+// Add sets the vendor for an OUI, e.g. for devices the registry doesn't know about
+func (d *OUIDatabase) Add(oui OUI, vendor string) {
+	d.loadEmbedded()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.vendors[oui] = vendor
+}
+
+func (d *OUIDatabase) Len() int {
+	d.loadEmbedded()
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.vendors)
+}
+
+// Lookup returns the vendor that owns the MAC's OUI. Locally administered addresses don't belong to a vendor and
+// never match.
+func (d *OUIDatabase) Lookup(mac MAC) (string, bool) {
+	if mac.IsLocallyAdministered() {
+		return "", false
+	}
+	d.loadEmbedded()
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	vendor, ok := d.vendors[mac.OUI()]
+	return vendor, ok
+}
+
+func parseOUIs(r io.Reader) (map[OUI]string, error) {
+	reader := bufio.NewReader(r)
+	start, err := reader.Peek(len("Registry,"))
+	if err == nil && string(start) == "Registry," {
+		return parseOUICSV(reader)
+	}
+
+	// Only the "XX-XX-XX   (hex)   Organization" lines matter, the registry is big enough that a regexp is slow
+	vendors := map[OUI]string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		prefix, vendor, ok := strings.Cut(scanner.Text(), "(hex)")
+		prefix, vendor = strings.TrimSpace(prefix), strings.TrimSpace(vendor)
+		if !ok || vendor == "" || len(prefix) != 8 || prefix[2] != '-' || prefix[5] != '-' {
+			continue
+		}
+		oui, err := parseOUI(prefix[0:2] + prefix[3:5] + prefix[6:8])
+		if err != nil {
+			continue
+		}
+		vendors[oui] = vendor
+	}
+	return vendors, scanner.Err()
+}
+
+// The CSV has a Registry,Assignment,Organization Name,Organization Address header
+func parseOUICSV(r io.Reader) (map[OUI]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	if _, err := reader.Read(); err != nil {
+		return nil, err
+	}
+	vendors := map[OUI]string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return vendors, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 || record[0] != "MA-L" {
+			continue
+		}
+		oui, err := parseOUI(record[1])
+		if err != nil {
+			return nil, err
+		}
+		vendors[oui] = strings.TrimSpace(record[2])
+	}
+}
+
+func parseOUI(s string) (OUI, error) {
+	var oui OUI
+	if len(s) != 6 {
+		return oui, fmt.Errorf("invalid OUI %q", s)
+	}
+	if _, err := hex.Decode(oui[:], []byte(s)); err != nil {
+		return oui, fmt.Errorf("invalid OUI %q", s)
+	}
+	return oui, nil
+}
+
+// EnrichClientVendors fills in Vendor on clients that don't have one, from db or DefaultOUIDatabase if it's nil.
+// Clients with randomised MACs have no vendor and are left alone, MAC.IsLocallyAdministered picks them out.
+// Returns the number of clients updated.
+func EnrichClientVendors(clients []ClientInfo, db *OUIDatabase) int {
+	if db == nil {
+		db = DefaultOUIDatabase
+	}
+	updated := 0
+	for i := range clients {
+		if vendor, ok := vendorFor(db, clients[i].MAC, clients[i].Vendor); ok {
+			clients[i].Vendor = vendor
+			updated++
+		}
+	}
+	return updated
+}
+
+// EnrichKnownClientVendors is EnrichClientVendors for the known client history
+func EnrichKnownClientVendors(knownClients []KnownClient, db *OUIDatabase) int {
+	if db == nil {
+		db = DefaultOUIDatabase
+	}
+	updated := 0
+	for i := range knownClients {
+		if vendor, ok := vendorFor(db, knownClients[i].MAC, knownClients[i].Vendor); ok {
+			knownClients[i].Vendor = vendor
+			updated++
+		}
+	}
+	return updated
+}
+
+func vendorFor(db *OUIDatabase, mac MAC, current string) (string, bool) {
+	if strings.TrimSpace(current) != "" || mac.IsZero() {
+		return "", false
+	}
+	return db.Lookup(mac)
+}
@@ -0,0 +1,93 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestOUIDatabase_LooksUpTheEmbeddedRegistry(t *testing.T) {
+	db := NewOUIDatabase()
+	// The whole registry, not a handful of common vendors
+	assert.Greater(t, db.Len(), 30000)
+
+	vendor, ok := db.Lookup(MustParseMAC("b8:27:eb:12:34:56"))
+	assert.True(t, ok)
+	assert.Equal(t, "Raspberry Pi Foundation", vendor)
+	vendor, _ = db.Lookup(MustParseMAC("50:c7:bf:00:00:01"))
+	assert.Equal(t, "TP-LINK TECHNOLOGIES CO.,LTD.", vendor)
+
+	_, ok = db.Lookup(MustParseMAC("12:34:56:78:9a:bc"))
+	assert.False(t, ok)
+}
+
+func TestOUIDatabase_LoadsTheIEEETextFormat(t *testing.T) {
+	db := NewOUIDatabase()
+	err := db.Load(strings.NewReader("OUI/MA-L\t\t\tOrganization\n" +
+		"company_id\t\tOrganization\n\n" +
+		"00-1A-2B   (hex)\t\tAcme Widgets Ltd\n" +
+		"001A2B     (base 16)\t\tAcme Widgets Ltd\n" +
+		"\t\t\t\t1 Example Road\n\n" +
+		"a4-b5-c6   (hex)\t\tExample Corp\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, db.Len())
+
+	vendor, ok := db.Lookup(MustParseMAC("00:1a:2b:00:00:01"))
+	assert.True(t, ok)
+	assert.Equal(t, "Acme Widgets Ltd", vendor)
+	vendor, _ = db.Lookup(MustParseMAC("a4:b5:c6:00:00:01"))
+	assert.Equal(t, "Example Corp", vendor)
+
+	// The embedded registry is replaced
+	_, ok = db.Lookup(MustParseMAC("b8:27:eb:12:34:56"))
+	assert.False(t, ok)
+}
+
+func TestOUIDatabase_LoadsTheIEEECSVFormat(t *testing.T) {
+	db := NewOUIDatabase()
+	err := db.Load(strings.NewReader("Registry,Assignment,Organization Name,Organization Address\n" +
+		"MA-L,001A2B,\"Acme Widgets, Ltd\",1 Example Road\n" +
+		"MA-M,A4B5C61,Too Specific,Elsewhere\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, db.Len())
+	vendor, _ := db.Lookup(MustParseMAC("00:1a:2b:00:00:01"))
+	assert.Equal(t, "Acme Widgets, Ltd", vendor)
+}
+
+func TestOUIDatabase_RejectsEmptyRegistries(t *testing.T) {
+	db := NewOUIDatabase()
+	assert.EqualError(t, db.Load(strings.NewReader("nothing here\n")), "no OUIs found")
+	assert.Greater(t, db.Len(), 30000)
+}
+
+func TestOUIDatabase_LocallyAdministeredAddressesNeverMatch(t *testing.T) {
+	db := NewOUIDatabase()
+	db.Add(OUI{0xda, 0xa1, 0x19}, "Not A Vendor")
+	_, ok := db.Lookup(MustParseMAC("da:a1:19:00:00:01"))
+	assert.False(t, ok)
+}
+
+func TestEnrichClientVendors_FillsMissingVendors(t *testing.T) {
+	db := NewOUIDatabase()
+	assert.NoError(t, db.Load(strings.NewReader("00-1A-2B   (hex)\t\tAcme Widgets Ltd\n")))
+	clients := []ClientInfo{
+		testClient("00:1a:2b:00:00:01", ClientInfo{}),
+		testClient("00:1a:2b:00:00:02", ClientInfo{Vendor: "Reported By Controller"}),
+		testClient("da:a1:19:00:00:03", ClientInfo{}),
+		testClient("12:34:56:00:00:04", ClientInfo{}),
+		testClient("10:20:30:00:00:05", ClientInfo{}),
+	}
+
+	assert.Equal(t, 1, EnrichClientVendors(clients, db))
+	assert.Equal(t, "Acme Widgets Ltd", clients[0].Vendor)
+	assert.Equal(t, "Reported By Controller", clients[1].Vendor)
+	// Randomised MACs don't belong to a vendor
+	assert.Equal(t, "", clients[2].Vendor)
+	assert.True(t, clients[2].MAC.IsLocallyAdministered())
+	assert.Equal(t, "", clients[3].Vendor)
+	assert.Equal(t, "", clients[4].Vendor)
+
+	knownClients := []KnownClient{{MAC: MustParseMAC("b8:27:eb:00:00:01")}}
+	assert.Equal(t, 1, EnrichKnownClientVendors(knownClients, nil))
+	assert.Equal(t, "Raspberry Pi Foundation", knownClients[0].Vendor)
+}
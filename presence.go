@@ -0,0 +1,214 @@
+package omada
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type PresenceState int
+
+const (
+	// Nothing has been observed yet
+	PresenceUnknown PresenceState = iota
+	PresenceHome
+	PresenceAway
+)
+
+func (s PresenceState) String() string {
+	switch s {
+	case PresenceUnknown:
+		return "unknown"
+	case PresenceHome:
+		return "home"
+	case PresenceAway:
+		return "away"
+	}
+	return fmt.Sprintf("PresenceState(%d)", int(s))
+}
+
+type PresenceChange struct {
+	Name     string
+	State    PresenceState
+	Previous PresenceState
+	// When any of the person's devices was last seen, zero if never
+	LastSeen time.Time
+	Time     time.Time
+}
+
+type PresenceConfig struct {
+	// The devices that belong to each person, or to anything else being tracked
+	People map[string][]MAC
+	// Sites to look for devices on, every site if empty
+	SiteIds []string
+	// How long after a person's devices were last seen they count as away, defaults to 5 minutes. Phones drop off
+	// the network while asleep, so this wants to be longer than that.
+	AwayTimeout time.Duration
+	// How often Run polls, defaults to 30 seconds
+	Interval time.Duration
+	// Called for every change of state, including the first observation from PresenceUnknown
+	OnChange func(change PresenceChange)
+	// Called when a poll fails. Nobody changes state on a failed poll.
+	OnError func(err error)
+}
+
+// PresenceTracker answers "is this person on the network" from the client list
+type PresenceTracker struct {
+	client  *OmadaClient
+	config  PresenceConfig
+	tracked map[MAC]bool
+	now     func() time.Time
+
+	mu       sync.Mutex
+	lastSeen map[MAC]time.Time
+	states   map[string]PresenceState
+}
+
+func NewPresenceTracker(client *OmadaClient, config PresenceConfig) *PresenceTracker {
+	if config.AwayTimeout <= 0 {
+		config.AwayTimeout = 5 * time.Minute
+	}
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	states := map[string]PresenceState{}
+	tracked := map[MAC]bool{}
+	for name, macs := range config.People {
+		states[name] = PresenceUnknown
+		for _, mac := range macs {
+			tracked[mac] = true
+		}
+	}
+	return &PresenceTracker{
+		client:   client,
+		config:   config,
+		tracked:  tracked,
+		now:      time.Now,
+		lastSeen: map[MAC]time.Time{},
+		states:   states,
+	}
+}
+
+// Run polls immediately and then every Interval until ctx is cancelled
+func (p *PresenceTracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		p.poll()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *PresenceTracker) poll() {
+	siteIds := p.config.SiteIds
+	if len(siteIds) == 0 {
+		sites, err := p.client.GetAllSites()
+		if err != nil {
+			p.reportError(fmt.Errorf("could not list sites: %w", err))
+			return
+		}
+		for _, site := range sites {
+			siteIds = append(siteIds, site.SiteId)
+		}
+	}
+
+	var clients []ClientInfo
+	for _, siteId := range siteIds {
+		siteClients, err := p.client.GetAllClients(siteId, ClientListQuery{})
+		if err != nil {
+			// A partial list would make people on the failed site look away
+			p.reportError(fmt.Errorf("could not list clients for site %s: %w", siteId, err))
+			return
+		}
+		clients = append(clients, siteClients...)
+	}
+	p.Observe(clients)
+}
+
+// Observe updates everyone's state from a client list, calling OnChange for and returning the changes in name
+// order. Run calls it on every poll, it can also be fed client lists fetched elsewhere.
+func (p *PresenceTracker) Observe(clients []ClientInfo) []PresenceChange {
+	p.mu.Lock()
+	now := p.now()
+	for _, client := range clients {
+		if !p.tracked[client.MAC] {
+			continue
+		}
+		// Listed clients are connected, the controller's LastSeen says when they last passed traffic
+		seen := client.LastSeenTime()
+		if seen.IsZero() || seen.After(now) {
+			seen = now
+		}
+		if seen.After(p.lastSeen[client.MAC]) {
+			p.lastSeen[client.MAC] = seen
+		}
+	}
+
+	var changes []PresenceChange
+	for name, macs := range p.config.People {
+		lastSeen := p.personLastSeen(macs)
+		state := PresenceAway
+		if !lastSeen.IsZero() && now.Sub(lastSeen) <= p.config.AwayTimeout {
+			state = PresenceHome
+		}
+		if previous := p.states[name]; previous != state {
+			p.states[name] = state
+			changes = append(changes, PresenceChange{Name: name, State: state, Previous: previous, LastSeen: lastSeen, Time: now})
+		}
+	}
+	p.mu.Unlock()
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	if p.config.OnChange != nil {
+		for _, change := range changes {
+			p.config.OnChange(change)
+		}
+	}
+	return changes
+}
+
+func (p *PresenceTracker) personLastSeen(macs []MAC) time.Time {
+	var lastSeen time.Time
+	for _, mac := range macs {
+		if seen := p.lastSeen[mac]; seen.After(lastSeen) {
+			lastSeen = seen
+		}
+	}
+	return lastSeen
+}
+
+// State is PresenceUnknown for names that aren't tracked
+func (p *PresenceTracker) State(name string) PresenceState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.states[name]
+}
+
+func (p *PresenceTracker) States() map[string]PresenceState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	states := make(map[string]PresenceState, len(p.states))
+	for name, state := range p.states {
+		states[name] = state
+	}
+	return states
+}
+
+// LastSeen returns when any of the person's devices was last seen, zero if never
+func (p *PresenceTracker) LastSeen(name string) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.personLastSeen(p.config.People[name])
+}
+
+func (p *PresenceTracker) reportError(err error) {
+	if p.config.OnError != nil {
+		p.config.OnError(err)
+	}
+}
@@ -0,0 +1,103 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPresenceTracker_AppliesTheAwayTimeout(t *testing.T) {
+	now := time.Date(2023, 10, 31, 8, 0, 0, 0, time.UTC)
+	var callbacks []PresenceChange
+	tracker := NewPresenceTracker(nil, PresenceConfig{
+		People: map[string][]MAC{
+			"alice": {MustParseMAC("11:11:11:11:11:11"), MustParseMAC("12:12:12:12:12:12")},
+			"bob":   {MustParseMAC("22:22:22:22:22:22")},
+		},
+		AwayTimeout: 10 * time.Minute,
+		OnChange:    func(change PresenceChange) { callbacks = append(callbacks, change) },
+	})
+	tracker.now = func() time.Time { return now }
+	assert.Equal(t, PresenceUnknown, tracker.State("alice"))
+
+	changes := tracker.Observe([]ClientInfo{testClient("11:11:11:11:11:11", ClientInfo{LastSeen: int(now.Add(-time.Minute).UnixMilli())})})
+	assert.Equal(t, []PresenceChange{
+		{Name: "alice", State: PresenceHome, Previous: PresenceUnknown, LastSeen: now.Add(-time.Minute), Time: now},
+		{Name: "bob", State: PresenceAway, Previous: PresenceUnknown, Time: now},
+	}, changes)
+	assert.Equal(t, changes, callbacks)
+
+	// Alice's phone drops off but her laptop is still around
+	now = now.Add(5 * time.Minute)
+	assert.Empty(t, tracker.Observe([]ClientInfo{testClient("12:12:12:12:12:12", ClientInfo{})}))
+	assert.Equal(t, now, tracker.LastSeen("alice"))
+
+	// Still within the timeout after everything leaves
+	now = now.Add(9 * time.Minute)
+	assert.Empty(t, tracker.Observe(nil))
+	assert.Equal(t, PresenceHome, tracker.State("alice"))
+
+	now = now.Add(2 * time.Minute)
+	changes = tracker.Observe([]ClientInfo{testClient("22:22:22:22:22:22", ClientInfo{LastSeen: int(now.UnixMilli())})})
+	assert.Equal(t, []PresenceChange{
+		{Name: "alice", State: PresenceAway, Previous: PresenceHome, LastSeen: now.Add(-11 * time.Minute), Time: now},
+		{Name: "bob", State: PresenceHome, Previous: PresenceAway, LastSeen: now, Time: now},
+	}, changes)
+	assert.Equal(t, map[string]PresenceState{"alice": PresenceAway, "bob": PresenceHome}, tracker.States())
+	assert.Len(t, callbacks, 4)
+}
+
+func TestPresenceTracker_UsesLastSeenForIdleDevices(t *testing.T) {
+	now := time.Date(2023, 10, 31, 8, 0, 0, 0, time.UTC)
+	tracker := NewPresenceTracker(nil, PresenceConfig{People: map[string][]MAC{"alice": {MustParseMAC("11:11:11:11:11:11")}}})
+	tracker.now = func() time.Time { return now }
+
+	// Listed, but the controller hasn't seen traffic from it for longer than the default timeout
+	tracker.Observe([]ClientInfo{testClient("11:11:11:11:11:11", ClientInfo{LastSeen: int(now.Add(-6 * time.Minute).UnixMilli())})})
+	assert.Equal(t, PresenceAway, tracker.State("alice"))
+	assert.Equal(t, PresenceUnknown, tracker.State("nobody"))
+}
+
+func TestPresenceTracker_OnlyRemembersTrackedDevices(t *testing.T) {
+	tracker := NewPresenceTracker(nil, PresenceConfig{People: map[string][]MAC{"alice": {MustParseMAC("11:11:11:11:11:11")}}})
+	tracker.Observe([]ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{}),
+		testClient("22:22:22:22:22:22", ClientInfo{}),
+		testClient("33:33:33:33:33:33", ClientInfo{}),
+	})
+	assert.Equal(t, PresenceHome, tracker.State("alice"))
+	assert.Len(t, tracker.lastSeen, 1)
+}
+
+func TestPresenceTracker_PollsEverySiteAndIgnoresFailedPolls(t *testing.T) {
+	controller, server := newMockClientListController(t)
+	defer server.Close()
+	now := time.Date(2023, 10, 31, 8, 0, 0, 0, time.UTC)
+	controller.set("site-a", testClient("11:11:11:11:11:11", ClientInfo{}))
+	controller.set("site-b", testClient("22:22:22:22:22:22", ClientInfo{}))
+
+	var errs []error
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	tracker := NewPresenceTracker(c, PresenceConfig{
+		People: map[string][]MAC{
+			"alice": {MustParseMAC("11:11:11:11:11:11")},
+			"bob":   {MustParseMAC("22:22:22:22:22:22")},
+		},
+		OnError: func(err error) { errs = append(errs, err) },
+	})
+	tracker.now = func() time.Time { return now }
+
+	tracker.poll()
+	assert.Equal(t, map[string]PresenceState{"alice": PresenceHome, "bob": PresenceHome}, tracker.States())
+
+	controller.set("site-a")
+	controller.fail("site-b", true)
+	now = now.Add(time.Hour)
+	tracker.poll()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, map[string]PresenceState{"alice": PresenceHome, "bob": PresenceHome}, tracker.States())
+
+	controller.fail("site-b", false)
+	tracker.poll()
+	assert.Equal(t, map[string]PresenceState{"alice": PresenceAway, "bob": PresenceHome}, tracker.States())
+}
@@ -0,0 +1,223 @@
+package omada
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ControllerConfig describes how to reach and authenticate against a single controller
+type ControllerConfig struct {
+	Name         string              `json:"name"`
+	BaseUrl      string              `json:"baseUrl"`
+	FailoverUrls []string            `json:"failoverUrls"`
+	OmadaCId     string              `json:"omadacId"`
+	ClientId     string              `json:"clientId"`
+	ClientSecret string              `json:"clientSecret"`
+	PageSize     int                 `json:"pageSize"`
+	TLS          ControllerTLSConfig `json:"tls"`
+}
+
+type ControllerTLSConfig struct {
+	DisableCertVerification bool `json:"disableCertVerification"`
+	// PEM encoded CA certificate(s) to trust in addition to the system pool, for self-signed controllers
+	CACertFile string `json:"caCertFile"`
+}
+
+type RegistryConfig struct {
+	Controllers []ControllerConfig `json:"controllers"`
+}
+
+// Registry holds the configuration for many controllers and lazily creates an OmadaClient for each on first use
+type Registry struct {
+	// Maximum number of controllers called at once by FanOut and HealthCheck, 0 for unlimited
+	MaxConcurrency int
+
+	mu      sync.Mutex
+	names   []string
+	configs map[string]ControllerConfig
+	clients map[string]*OmadaClient
+}
+
+type ControllerHealth struct {
+	Name    string
+	Healthy bool
+	Latency time.Duration
+	Err     error
+}
+
+type FanOutResult[T any] struct {
+	Name   string
+	Result T
+	Err    error
+}
+
+func NewRegistry(controllers []ControllerConfig) (*Registry, error) {
+	r := &Registry{
+		configs: map[string]ControllerConfig{},
+		clients: map[string]*OmadaClient{},
+	}
+	for _, controller := range controllers {
+		if controller.Name == "" {
+			return nil, fmt.Errorf("controller with base url %q has no name", controller.BaseUrl)
+		}
+		if controller.BaseUrl == "" || controller.OmadaCId == "" {
+			return nil, fmt.Errorf("controller %s: baseUrl and omadacId are required", controller.Name)
+		}
+		if _, exists := r.configs[controller.Name]; exists {
+			return nil, fmt.Errorf("duplicate controller name %s", controller.Name)
+		}
+		r.configs[controller.Name] = controller
+		r.names = append(r.names, controller.Name)
+	}
+	sort.Strings(r.names)
+	return r, nil
+}
+
+// LoadRegistry reads a JSON encoded RegistryConfig
+func LoadRegistry(reader io.Reader) (*Registry, error) {
+	config := &RegistryConfig{}
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("could not decode registry config: %w", err)
+	}
+	return NewRegistry(config.Controllers)
+}
+
+func LoadRegistryFile(path string) (*Registry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadRegistry(file)
+}
+
+// Names returns the configured controller names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, len(r.names))
+	copy(names, r.names)
+	return names
+}
+
+// Client returns the client for the named controller, creating it on first use
+func (r *Registry) Client(name string) (*OmadaClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client, ok := r.clients[name]; ok {
+		return client, nil
+	}
+	config, ok := r.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown controller %s", name)
+	}
+	client, err := newClientFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("controller %s: %w", name, err)
+	}
+	r.clients[name] = client
+	return client, nil
+}
+
+// HealthCheck authenticates against every controller and makes a lightweight API call, reporting the outcome of each.
+// The call always goes to the controller, even when the client caches its response.
+func (r *Registry) HealthCheck() []ControllerHealth {
+	results := FanOut(r, func(name string, client *OmadaClient) (time.Duration, error) {
+		start := time.Now()
+		path := fmt.Sprintf("%s/openapi/v1/%s/scenarios", client.ActiveBaseUrl(), client.omadaCId)
+		request, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			return 0, err
+		}
+		scenarios := &GetScenarioListResponse{}
+		if err := client.httpDoWrapped(request, scenarios); err != nil {
+			return 0, err
+		}
+		if err := scenarios.Err(); err != nil {
+			return 0, err
+		}
+		return time.Since(start), nil
+	})
+
+	health := make([]ControllerHealth, len(results))
+	for i, result := range results {
+		health[i] = ControllerHealth{
+			Name:    result.Name,
+			Healthy: result.Err == nil,
+			Latency: result.Result,
+			Err:     result.Err,
+		}
+	}
+	return health
+}
+
+// FanOut calls fn concurrently for every controller in the registry and returns the results in controller name
+// order. A controller whose client cannot be created is reported with its error and fn is not called for it.
+func FanOut[T any](r *Registry, fn func(name string, client *OmadaClient) (T, error)) []FanOutResult[T] {
+	names := r.Names()
+	results := make([]FanOutResult[T], len(names))
+
+	limit := r.MaxConcurrency
+	if limit <= 0 || limit > len(names) {
+		limit = len(names)
+	}
+	semaphore := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[i].Name = name
+			client, err := r.Client(name)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			results[i].Result, results[i].Err = fn(name, client)
+		}(i, name)
+	}
+	wg.Wait()
+	return results
+}
+
+func newClientFromConfig(config ControllerConfig) (*OmadaClient, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.TLS.DisableCertVerification}
+	if config.TLS.CACertFile != "" {
+		pem, err := os.ReadFile(config.TLS.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLS.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+	client := NewClientWithHttpClient(config.BaseUrl, config.OmadaCId, config.ClientId, config.ClientSecret, httpClient)
+	if len(config.FailoverUrls) > 0 {
+		client.SetFailoverBaseUrls(config.FailoverUrls...)
+	}
+	if config.PageSize > 0 {
+		client.PageSize = config.PageSize
+	}
+	return client, nil
+}
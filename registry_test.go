@@ -0,0 +1,166 @@
+package omada
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mockScenarioServer(t *testing.T, errorCode int) *httptest.Server {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/scenarios", func(w http.ResponseWriter, r *http.Request) {
+		if errorCode != 0 {
+			_, err := w.Write([]byte(`{"errorCode": -1, "msg": "General error."}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := w.Write([]byte(`{"errorCode": 0, "msg": "Success.", "result": ["Hotel"]}`))
+		assert.NoError(t, err)
+	})
+	return httptest.NewServer(mockMux)
+}
+
+func testControllerConfig(name, baseUrl string) ControllerConfig {
+	return ControllerConfig{
+		Name:         name,
+		BaseUrl:      baseUrl,
+		OmadaCId:     "my-cid",
+		ClientId:     "my-client-id",
+		ClientSecret: "my-client-secret",
+	}
+}
+
+func TestLoadRegistry_ParsesControllerDefinitions(t *testing.T) {
+	registry, err := LoadRegistry(strings.NewReader(`{
+		"controllers": [
+			{
+				"name": "customer-b",
+				"baseUrl": "https://b.example.com:8043",
+				"omadacId": "cid-b",
+				"clientId": "id-b",
+				"clientSecret": "secret-b",
+				"pageSize": 50,
+				"tls": {"disableCertVerification": true}
+			},
+			{"name": "customer-a", "baseUrl": "https://a.example.com:8043", "omadacId": "cid-a"}
+		]
+	}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"customer-a", "customer-b"}, registry.Names())
+	client, err := registry.Client("customer-b")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://b.example.com:8043", client.ActiveBaseUrl())
+	assert.Equal(t, "cid-b", client.omadaCId)
+	assert.Equal(t, 50, client.PageSize)
+}
+
+func TestLoadRegistry_RejectsInvalidConfig(t *testing.T) {
+	_, err := LoadRegistry(strings.NewReader(`{"controllers": [{"name": "a", "baseUrl": "https://a", "omadacId": "x"}, {"name": "a", "baseUrl": "https://b", "omadacId": "y"}]}`))
+	assert.EqualError(t, err, "duplicate controller name a")
+
+	_, err = LoadRegistry(strings.NewReader(`{"controllers": [{"name": "a"}]}`))
+	assert.EqualError(t, err, "controller a: baseUrl and omadacId are required")
+
+	_, err = LoadRegistry(strings.NewReader(`{"controllers": [{"nmae": "a"}]}`))
+	assert.Error(t, err)
+}
+
+func TestRegistry_Client_CreatesClientsLazilyAndReusesThem(t *testing.T) {
+	registry, err := NewRegistry([]ControllerConfig{testControllerConfig("a", "https://a.example.com")})
+	assert.NoError(t, err)
+	assert.Empty(t, registry.clients)
+
+	first, err := registry.Client("a")
+	assert.NoError(t, err)
+	second, err := registry.Client("a")
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+
+	_, err = registry.Client("missing")
+	assert.EqualError(t, err, "unknown controller missing")
+}
+
+func TestRegistry_HealthCheck_ReportsEachController(t *testing.T) {
+	healthy := mockScenarioServer(t, 0)
+	defer healthy.Close()
+	erroring := mockScenarioServer(t, -1)
+	defer erroring.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	registry, err := NewRegistry([]ControllerConfig{
+		testControllerConfig("healthy", healthy.URL),
+		testControllerConfig("erroring", erroring.URL),
+		testControllerConfig("unreachable", unreachable.URL),
+	})
+	assert.NoError(t, err)
+
+	health := registry.HealthCheck()
+
+	assert.Len(t, health, 3)
+	assert.Equal(t, "erroring", health[0].Name)
+	assert.False(t, health[0].Healthy)
+	assert.EqualError(t, health[0].Err, "error response: -1: General error.")
+	assert.Equal(t, "healthy", health[1].Name)
+	assert.True(t, health[1].Healthy)
+	assert.NoError(t, health[1].Err)
+	assert.Equal(t, "unreachable", health[2].Name)
+	assert.False(t, health[2].Healthy)
+	assert.Error(t, health[2].Err)
+}
+
+func TestRegistry_HealthCheck_BypassesTheCache(t *testing.T) {
+	server := mockScenarioServer(t, 0)
+	registry, err := NewRegistry([]ControllerConfig{testControllerConfig("cached", server.URL)})
+	assert.NoError(t, err)
+	client, err := registry.Client("cached")
+	assert.NoError(t, err)
+	client.EnableCache(map[CacheEndpoint]time.Duration{CacheEndpointScenarioList: time.Hour})
+
+	assert.True(t, registry.HealthCheck()[0].Healthy)
+	_, err = client.GetScenarioList()
+	assert.NoError(t, err)
+
+	server.Close()
+	health := registry.HealthCheck()
+	assert.False(t, health[0].Healthy)
+	assert.Error(t, health[0].Err)
+}
+
+func TestFanOut_AggregatesResultsAcrossControllers(t *testing.T) {
+	server := mockScenarioServer(t, 0)
+	defer server.Close()
+
+	registry, err := NewRegistry([]ControllerConfig{
+		testControllerConfig("b", server.URL),
+		testControllerConfig("a", server.URL),
+		testControllerConfig("c", server.URL),
+	})
+	assert.NoError(t, err)
+	registry.MaxConcurrency = 1
+
+	results := FanOut(registry, func(name string, client *OmadaClient) ([]string, error) {
+		if name == "c" {
+			return nil, errors.New("boom")
+		}
+		scenarios, err := client.GetScenarioList()
+		if err != nil {
+			return nil, err
+		}
+		return scenarios.Result, nil
+	})
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, "a", results[0].Name)
+	assert.Equal(t, []string{"Hotel"}, results[0].Result)
+	assert.Equal(t, "b", results[1].Name)
+	assert.Equal(t, []string{"Hotel"}, results[1].Result)
+	assert.Equal(t, "c", results[2].Name)
+	assert.EqualError(t, results[2].Err, "boom")
+}
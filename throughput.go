@@ -0,0 +1,142 @@
+package omada
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type ClientThroughput struct {
+	SiteId            string
+	MAC               MAC
+	Name              string
+	DownBytesPerSec   float64
+	UpBytesPerSec     float64
+	DownPacketsPerSec float64
+	UpPacketsPerSec   float64
+	// The time between the two samples the rates were computed from
+	Interval time.Duration
+	At       time.Time
+}
+
+func (t ClientThroughput) TotalBytesPerSec() float64 {
+	return t.DownBytesPerSec + t.UpBytesPerSec
+}
+
+// ThroughputSampler turns the cumulative traffic counters in ClientInfo into rates by remembering each client's
+// counters from the previous sample. Feed it each site's client list each time it's polled.
+type ThroughputSampler struct {
+	mu      sync.Mutex
+	samples map[string]map[MAC]trafficSample
+	rates   map[string]map[MAC]ClientThroughput
+	now     func() time.Time
+}
+
+type trafficSample struct {
+	at          time.Time
+	down        int64
+	up          int64
+	downPackets int64
+	upPackets   int64
+	uptime      int
+}
+
+func NewThroughputSampler() *ThroughputSampler {
+	return &ThroughputSampler{
+		samples: map[string]map[MAC]trafficSample{},
+		rates:   map[string]map[MAC]ClientThroughput{},
+		now:     time.Now,
+	}
+}
+
+// Sample records the counters for the site's clients and returns the rates for every client that was also in the
+// site's previous sample. Clients that have reconnected since, so their counters went backwards, start again from
+// this sample. Clients missing from clients are forgotten, other sites are left alone.
+func (s *ThroughputSampler) Sample(siteId string, clients []ClientInfo) []ClientThroughput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	samples := make(map[MAC]trafficSample, len(clients))
+	rates := make(map[MAC]ClientThroughput, len(clients))
+	var results []ClientThroughput
+	for _, client := range clients {
+		current := trafficSample{
+			at:          now,
+			down:        int64(client.TrafficDown),
+			up:          int64(client.TrafficUp),
+			downPackets: int64(client.DownPacket),
+			upPackets:   int64(client.UpPacket),
+			uptime:      client.Uptime,
+		}
+		samples[client.MAC] = current
+
+		previous, ok := s.samples[siteId][client.MAC]
+		if !ok || counterReset(previous, current) {
+			continue
+		}
+		interval := current.at.Sub(previous.at)
+		if interval <= 0 {
+			continue
+		}
+		seconds := interval.Seconds()
+		rate := ClientThroughput{
+			SiteId:            siteId,
+			MAC:               client.MAC,
+			Name:              client.Name,
+			DownBytesPerSec:   float64(current.down-previous.down) / seconds,
+			UpBytesPerSec:     float64(current.up-previous.up) / seconds,
+			DownPacketsPerSec: float64(current.downPackets-previous.downPackets) / seconds,
+			UpPacketsPerSec:   float64(current.upPackets-previous.upPackets) / seconds,
+			Interval:          interval,
+			At:                now,
+		}
+		rates[client.MAC] = rate
+		results = append(results, rate)
+	}
+	s.samples[siteId] = samples
+	s.rates[siteId] = rates
+	return results
+}
+
+// Rate returns the client's throughput from the site's latest sample, false if there isn't one yet
+func (s *ThroughputSampler) Rate(siteId string, mac MAC) (ClientThroughput, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rate, ok := s.rates[siteId][mac]
+	return rate, ok
+}
+
+// TopTalkers returns up to n clients across every site with the highest combined down and up rate from their
+// site's latest sample
+func (s *ThroughputSampler) TopTalkers(n int) []ClientThroughput {
+	s.mu.Lock()
+	var rates []ClientThroughput
+	for _, siteRates := range s.rates {
+		for _, rate := range siteRates {
+			rates = append(rates, rate)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].TotalBytesPerSec() != rates[j].TotalBytesPerSec() {
+			return rates[i].TotalBytesPerSec() > rates[j].TotalBytesPerSec()
+		}
+		if rates[i].MAC != rates[j].MAC {
+			return rates[i].MAC.String() < rates[j].MAC.String()
+		}
+		return rates[i].SiteId < rates[j].SiteId
+	})
+	if n >= 0 && n < len(rates) {
+		rates = rates[:n]
+	}
+	return rates
+}
+
+// The counters restart from zero when a client reconnects, which also resets its uptime
+func counterReset(previous, current trafficSample) bool {
+	return current.uptime < previous.uptime ||
+		current.down < previous.down || current.up < previous.up ||
+		current.downPackets < previous.downPackets || current.upPackets < previous.upPackets
+}
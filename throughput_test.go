@@ -0,0 +1,120 @@
+package omada
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestThroughputSampler_ComputesRatesBetweenSamples(t *testing.T) {
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	sampler := NewThroughputSampler()
+	sampler.now = func() time.Time { return now }
+
+	assert.Empty(t, sampler.Sample("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 1000, TrafficUp: 500, DownPacket: 10, UpPacket: 5, Uptime: 100}),
+	}))
+
+	now = now.Add(10 * time.Second)
+	rates := sampler.Sample("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 51000, TrafficUp: 1500, DownPacket: 110, UpPacket: 25, Uptime: 110}),
+	})
+
+	assert.Len(t, rates, 1)
+	assert.Equal(t, "site-a", rates[0].SiteId)
+	assert.Equal(t, MustParseMAC("11:11:11:11:11:11"), rates[0].MAC)
+	assert.Equal(t, 5000.0, rates[0].DownBytesPerSec)
+	assert.Equal(t, 100.0, rates[0].UpBytesPerSec)
+	assert.Equal(t, 10.0, rates[0].DownPacketsPerSec)
+	assert.Equal(t, 2.0, rates[0].UpPacketsPerSec)
+	assert.Equal(t, 10*time.Second, rates[0].Interval)
+
+	rate, ok := sampler.Rate("site-a", MustParseMAC("11:11:11:11:11:11"))
+	assert.True(t, ok)
+	assert.Equal(t, 5100.0, rate.TotalBytesPerSec())
+}
+
+func TestThroughputSampler_HandlesCounterResetsOnReconnect(t *testing.T) {
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	sampler := NewThroughputSampler()
+	sampler.now = func() time.Time { return now }
+
+	sampler.Sample("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 900000, TrafficUp: 9000, DownPacket: 900, UpPacket: 90, Uptime: 5000}),
+	})
+	now = now.Add(10 * time.Second)
+	assert.Empty(t, sampler.Sample("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 2000, TrafficUp: 100, DownPacket: 20, UpPacket: 2, Uptime: 5}),
+	}))
+	_, ok := sampler.Rate("site-a", MustParseMAC("11:11:11:11:11:11"))
+	assert.False(t, ok)
+
+	// The post-reconnect sample becomes the new baseline
+	now = now.Add(10 * time.Second)
+	rates := sampler.Sample("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 12000, TrafficUp: 200, DownPacket: 40, UpPacket: 4, Uptime: 15}),
+	})
+	assert.Len(t, rates, 1)
+	assert.Equal(t, 1000.0, rates[0].DownBytesPerSec)
+}
+
+func TestThroughputSampler_ForgetsClientsThatDisappear(t *testing.T) {
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	sampler := NewThroughputSampler()
+	sampler.now = func() time.Time { return now }
+
+	sampler.Sample("site-a", []ClientInfo{testClient("11:11:11:11:11:11", ClientInfo{Uptime: 1})})
+	now = now.Add(time.Second)
+	sampler.Sample("site-a", nil)
+	now = now.Add(time.Second)
+	assert.Empty(t, sampler.Sample("site-a", []ClientInfo{testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 100, DownPacket: 1, Uptime: 3})}))
+}
+
+func TestThroughputSampler_KeepsEachSiteSeparate(t *testing.T) {
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	sampler := NewThroughputSampler()
+	sampler.now = func() time.Time { return now }
+
+	sampler.Sample("site-a", []ClientInfo{testClient("11:11:11:11:11:11", ClientInfo{Uptime: 1})})
+	sampler.Sample("site-b", []ClientInfo{testClient("22:22:22:22:22:22", ClientInfo{Uptime: 1})})
+	now = now.Add(time.Second)
+	siteA := sampler.Sample("site-a", []ClientInfo{testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 100, Uptime: 2})})
+	siteB := sampler.Sample("site-b", []ClientInfo{testClient("22:22:22:22:22:22", ClientInfo{TrafficDown: 300, Uptime: 2})})
+
+	assert.Len(t, siteA, 1)
+	assert.Len(t, siteB, 1)
+	assert.Equal(t, "site-b", siteB[0].SiteId)
+	assert.Equal(t, 300.0, siteB[0].DownBytesPerSec)
+	_, ok := sampler.Rate("site-a", MustParseMAC("11:11:11:11:11:11"))
+	assert.True(t, ok)
+	_, ok = sampler.Rate("site-b", MustParseMAC("11:11:11:11:11:11"))
+	assert.False(t, ok)
+
+	top := sampler.TopTalkers(-1)
+	assert.Len(t, top, 2)
+	assert.Equal(t, "site-b", top[0].SiteId)
+}
+
+func TestThroughputSampler_TopTalkers(t *testing.T) {
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	sampler := NewThroughputSampler()
+	sampler.now = func() time.Time { return now }
+
+	sampler.Sample("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{Uptime: 1}),
+		testClient("22:22:22:22:22:22", ClientInfo{Uptime: 1}),
+		testClient("33:33:33:33:33:33", ClientInfo{Uptime: 1}),
+	})
+	now = now.Add(time.Second)
+	sampler.Sample("site-a", []ClientInfo{
+		testClient("11:11:11:11:11:11", ClientInfo{TrafficDown: 100, DownPacket: 1, Uptime: 2}),
+		testClient("22:22:22:22:22:22", ClientInfo{TrafficDown: 5000, TrafficUp: 5000, DownPacket: 10, UpPacket: 10, Uptime: 2}),
+		testClient("33:33:33:33:33:33", ClientInfo{TrafficDown: 300, TrafficUp: 300, DownPacket: 3, UpPacket: 3, Uptime: 2}),
+	})
+
+	top := sampler.TopTalkers(2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, MustParseMAC("22:22:22:22:22:22"), top[0].MAC)
+	assert.Equal(t, MustParseMAC("33:33:33:33:33:33"), top[1].MAC)
+	assert.Len(t, sampler.TopTalkers(10), 3)
+}
@@ -0,0 +1,309 @@
+package omada
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+type ClientEventType int
+
+const (
+	ClientJoined ClientEventType = iota
+	ClientLeft
+	// The client moved to a different AP
+	ClientRoamed
+	// The client moved to a different radio band on the same or another AP
+	ClientBandChanged
+	ClientIPChanged
+	ClientBlockedChanged
+)
+
+func (t ClientEventType) String() string {
+	switch t {
+	case ClientJoined:
+		return "joined"
+	case ClientLeft:
+		return "left"
+	case ClientRoamed:
+		return "roamed"
+	case ClientBandChanged:
+		return "band changed"
+	case ClientIPChanged:
+		return "ip changed"
+	case ClientBlockedChanged:
+		return "blocked changed"
+	}
+	return fmt.Sprintf("ClientEventType(%d)", int(t))
+}
+
+type ClientEvent struct {
+	Type   ClientEventType
+	SiteId string
+	MAC    MAC
+	// The client as it is now, or as it was last seen for ClientLeft
+	Client ClientInfo
+	// The client before the change, nil for ClientJoined
+	Previous *ClientInfo
+	Time     time.Time
+}
+
+type WatcherConfig struct {
+	// Sites to watch, every site on the controller (including ones added later) if empty
+	SiteIds []string
+	// How often to poll, defaults to 30 seconds
+	Interval time.Duration
+	// How long a change has to persist before it is reported. A client that leaves and comes back, or roams
+	// away and back, within this window produces no events.
+	Debounce time.Duration
+	// Report every client present on the first poll as joined, rather than treating it as the baseline
+	EmitInitial bool
+	// Size of the event channel buffer, defaults to 64
+	BufferSize int
+	// Called when a poll fails. Sites that can't be polled keep their previous state, so a failed poll never
+	// makes clients look like they've left.
+	OnError func(err error)
+}
+
+// Watcher polls the client list and reports clients joining, leaving and changing over a channel
+type Watcher struct {
+	client *OmadaClient
+	config WatcherConfig
+	events chan ClientEvent
+	now    func() time.Time
+	ran    atomic.Bool
+
+	initialised map[string]bool
+	clients     map[watchedClientKey]*watchedClient
+}
+
+type watchedClientKey struct {
+	siteId string
+	mac    MAC
+}
+
+type watchedClient struct {
+	// What was last reported, present is false once ClientLeft has been emitted
+	confirmed        ClientInfo
+	confirmedPresent bool
+	// What has been observed since candidateSince, waiting out the debounce
+	candidate        ClientInfo
+	candidatePresent bool
+	candidateSince   time.Time
+}
+
+func NewWatcher(client *OmadaClient, config WatcherConfig) *Watcher {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 64
+	}
+	return &Watcher{
+		client:      client,
+		config:      config,
+		events:      make(chan ClientEvent, config.BufferSize),
+		now:         time.Now,
+		initialised: map[string]bool{},
+		clients:     map[watchedClientKey]*watchedClient{},
+	}
+}
+
+// Events is closed when Run returns
+func (w *Watcher) Events() <-chan ClientEvent {
+	return w.events
+}
+
+// ErrWatcherAlreadyRun is returned by Run when called a second time, the events channel is closed after the first
+var ErrWatcherAlreadyRun = errors.New("watcher has already been run")
+
+// Run polls immediately and then every Interval until ctx is cancelled. A Watcher can only be run once.
+func (w *Watcher) Run(ctx context.Context) error {
+	if !w.ran.CompareAndSwap(false, true) {
+		return ErrWatcherAlreadyRun
+	}
+	defer close(w.events)
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+	for {
+		for _, event := range w.poll() {
+			select {
+			case w.events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *Watcher) poll() []ClientEvent {
+	var events []ClientEvent
+	siteIds := w.config.SiteIds
+	if len(siteIds) == 0 {
+		sites, err := w.client.GetAllSites()
+		if err != nil {
+			w.reportError(fmt.Errorf("could not list sites: %w", err))
+			return nil
+		}
+		for _, site := range sites {
+			siteIds = append(siteIds, site.SiteId)
+		}
+		// Sites that have been deleted from the controller are never polled again
+		events = w.forgetSitesExcept(siteIds)
+	}
+
+	for _, siteId := range siteIds {
+		clients, err := w.client.GetAllClients(siteId, ClientListQuery{})
+		if err != nil {
+			w.reportError(fmt.Errorf("could not list clients for site %s: %w", siteId, err))
+			continue
+		}
+		events = append(events, w.observe(siteId, clients)...)
+	}
+	return events
+}
+
+// forgetSitesExcept drops the state of sites not in siteIds, reporting their clients as having left
+func (w *Watcher) forgetSitesExcept(siteIds []string) []ClientEvent {
+	current := map[string]bool{}
+	for _, siteId := range siteIds {
+		current[siteId] = true
+	}
+	now := w.now()
+	var events []ClientEvent
+	for key, watched := range w.clients {
+		if current[key.siteId] {
+			continue
+		}
+		if watched.confirmedPresent {
+			events = append(events, ClientEvent{Type: ClientLeft, SiteId: key.siteId, MAC: key.mac, Client: watched.confirmed, Previous: &watched.confirmed, Time: now})
+		}
+		delete(w.clients, key)
+	}
+	for siteId := range w.initialised {
+		if !current[siteId] {
+			delete(w.initialised, siteId)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].SiteId != events[j].SiteId {
+			return events[i].SiteId < events[j].SiteId
+		}
+		return bytes.Compare(events[i].MAC[:], events[j].MAC[:]) < 0
+	})
+	return events
+}
+
+func (w *Watcher) observe(siteId string, clients []ClientInfo) []ClientEvent {
+	now := w.now()
+	baseline := !w.initialised[siteId] && !w.config.EmitInitial
+	w.initialised[siteId] = true
+
+	present := map[MAC]bool{}
+	var events []ClientEvent
+	for _, client := range clients {
+		if present[client.MAC] {
+			continue
+		}
+		present[client.MAC] = true
+		key := watchedClientKey{siteId: siteId, mac: client.MAC}
+		watched, ok := w.clients[key]
+		if !ok {
+			watched = &watchedClient{}
+			w.clients[key] = watched
+			if baseline {
+				watched.confirmed, watched.confirmedPresent = client, true
+			}
+		}
+		events = append(events, w.update(siteId, watched, client, true, now)...)
+	}
+	for key, watched := range w.clients {
+		if key.siteId != siteId {
+			continue
+		}
+		if !present[key.mac] {
+			events = append(events, w.update(siteId, watched, watched.candidate, false, now)...)
+			if !watched.confirmedPresent && !watched.candidatePresent {
+				delete(w.clients, key)
+			}
+		}
+	}
+	// Map iteration makes the order of departures random otherwise
+	sort.SliceStable(events, func(i, j int) bool {
+		return bytes.Compare(events[i].MAC[:], events[j].MAC[:]) < 0
+	})
+	return events
+}
+
+func (w *Watcher) update(siteId string, watched *watchedClient, observed ClientInfo, observedPresent bool, now time.Time) []ClientEvent {
+	if watched.candidateSince.IsZero() || watched.candidatePresent != observedPresent || !sameWatchedState(watched.candidate, observed) {
+		watched.candidateSince = now
+	}
+	watched.candidate, watched.candidatePresent = observed, observedPresent
+
+	unchanged := watched.confirmedPresent == observedPresent && (!observedPresent || sameWatchedState(watched.confirmed, observed))
+	if unchanged {
+		// Keep details that don't generate events, like signal and traffic, fresh for the next event
+		if observedPresent {
+			watched.confirmed = observed
+		}
+		return nil
+	}
+	if now.Sub(watched.candidateSince) < w.config.Debounce {
+		return nil
+	}
+
+	events := diffWatchedClient(siteId, watched.confirmed, watched.confirmedPresent, observed, observedPresent, now)
+	if observedPresent {
+		watched.confirmed = observed
+	}
+	watched.confirmedPresent = observedPresent
+	return events
+}
+
+func diffWatchedClient(siteId string, previous ClientInfo, previousPresent bool, current ClientInfo, currentPresent bool, now time.Time) []ClientEvent {
+	event := func(eventType ClientEventType, client ClientInfo, previous *ClientInfo) ClientEvent {
+		return ClientEvent{Type: eventType, SiteId: siteId, MAC: client.MAC, Client: client, Previous: previous, Time: now}
+	}
+	switch {
+	case !previousPresent && currentPresent:
+		return []ClientEvent{event(ClientJoined, current, nil)}
+	case previousPresent && !currentPresent:
+		return []ClientEvent{event(ClientLeft, previous, &previous)}
+	}
+
+	var events []ClientEvent
+	if previous.APMac != current.APMac {
+		events = append(events, event(ClientRoamed, current, &previous))
+	}
+	if previous.RadioId != current.RadioId || previous.Wireless != current.Wireless {
+		events = append(events, event(ClientBandChanged, current, &previous))
+	}
+	if previous.IP != current.IP {
+		events = append(events, event(ClientIPChanged, current, &previous))
+	}
+	if previous.Blocked != current.Blocked {
+		events = append(events, event(ClientBlockedChanged, current, &previous))
+	}
+	return events
+}
+
+// sameWatchedState compares only the fields that generate events
+func sameWatchedState(a, b ClientInfo) bool {
+	return a.APMac == b.APMac && a.RadioId == b.RadioId && a.Wireless == b.Wireless && a.IP == b.IP && a.Blocked == b.Blocked
+}
+
+func (w *Watcher) reportError(err error) {
+	if w.config.OnError != nil {
+		w.config.OnError(err)
+	}
+}
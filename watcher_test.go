@@ -0,0 +1,231 @@
+package omada
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockClientListController struct {
+	mu      sync.Mutex
+	clients map[string][]ClientInfo
+	failing map[string]bool
+}
+
+func (m *mockClientListController) set(siteId string, clients ...ClientInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[siteId] = clients
+}
+
+func (m *mockClientListController) remove(siteId string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.clients, siteId)
+}
+
+func (m *mockClientListController) fail(siteId string, failing bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failing[siteId] = failing
+}
+
+func newMockClientListController(t *testing.T) (*mockClientListController, *httptest.Server) {
+	controller := &mockClientListController{clients: map[string][]ClientInfo{}, failing: map[string]bool{}}
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/openapi/authorize/token", func(w http.ResponseWriter, r *http.Request) { mockValidTokenResponse(t, w, r) })
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites", func(w http.ResponseWriter, r *http.Request) {
+		controller.mu.Lock()
+		defer controller.mu.Unlock()
+		var sites []SiteEntity
+		for siteId := range controller.clients {
+			sites = append(sites, SiteEntity{SiteId: siteId})
+		}
+		encoded, err := json.Marshal(sites)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(fmt.Sprintf(`{"errorCode": 0, "result": {"totalRows": %d, "data": %s}}`, len(sites), encoded)))
+		assert.NoError(t, err)
+	})
+	mockMux.HandleFunc("/openapi/v1/my-cid/sites/", func(w http.ResponseWriter, r *http.Request) {
+		controller.mu.Lock()
+		defer controller.mu.Unlock()
+		var siteId string
+		_, err := fmt.Sscanf(r.URL.Path, "/openapi/v1/my-cid/sites/%s", &siteId)
+		assert.NoError(t, err)
+		siteId = siteId[:len(siteId)-len("/clients")]
+		if controller.failing[siteId] {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		encoded, err := json.Marshal(controller.clients[siteId])
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(fmt.Sprintf(`{"errorCode": 0, "result": {"totalRows": %d, "data": %s}}`, len(controller.clients[siteId]), encoded)))
+		assert.NoError(t, err)
+	})
+	return controller, httptest.NewServer(mockMux)
+}
+
+func eventSummaries(events []ClientEvent) []string {
+	var summaries []string
+	for _, event := range events {
+		summaries = append(summaries, fmt.Sprintf("%s %s %s", event.SiteId, event.MAC, event.Type))
+	}
+	return summaries
+}
+
+func TestWatcher_EmitsJoinLeaveAndChangeEvents(t *testing.T) {
+	controller, server := newMockClientListController(t)
+	defer server.Close()
+
+	phone := testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g, IP: "10.0.0.11"})
+	laptop := testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId2g, IP: "10.0.0.22"})
+	controller.set("site-a", phone, laptop)
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	watcher := NewWatcher(c, WatcherConfig{SiteIds: []string{"site-a"}})
+
+	// The first poll is the baseline
+	assert.Empty(t, watcher.poll())
+
+	roamed := phone
+	roamed.APMac = MustParseMAC("aa:aa:aa:aa:aa:02")
+	roamed.RadioId = RadioId6g
+	renumbered := laptop
+	renumbered.IP = "10.0.0.23"
+	renumbered.Blocked = true
+	tablet := testClient("33:33:33:33:33:33", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g, IP: "10.0.0.33"})
+	controller.set("site-a", roamed, renumbered, tablet)
+
+	events := watcher.poll()
+	assert.Equal(t, []string{
+		"site-a 11-11-11-11-11-11 roamed",
+		"site-a 11-11-11-11-11-11 band changed",
+		"site-a 22-22-22-22-22-22 ip changed",
+		"site-a 22-22-22-22-22-22 blocked changed",
+		"site-a 33-33-33-33-33-33 joined",
+	}, eventSummaries(events))
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:01"), events[0].Previous.APMac)
+	assert.Equal(t, MustParseMAC("aa:aa:aa:aa:aa:02"), events[0].Client.APMac)
+	assert.Nil(t, events[4].Previous)
+
+	controller.set("site-a", tablet)
+	events = watcher.poll()
+	assert.Equal(t, []string{"site-a 11-11-11-11-11-11 left", "site-a 22-22-22-22-22-22 left"}, eventSummaries(events))
+	assert.Equal(t, "10.0.0.23", events[1].Client.IP)
+
+	assert.Empty(t, watcher.poll())
+}
+
+func TestWatcher_DebounceSuppressesFlapping(t *testing.T) {
+	controller, server := newMockClientListController(t)
+	defer server.Close()
+
+	phone := testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g, IP: "10.0.0.11"})
+	controller.set("site-a", phone)
+
+	now := time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC)
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	watcher := NewWatcher(c, WatcherConfig{SiteIds: []string{"site-a"}, Debounce: time.Minute})
+	watcher.now = func() time.Time { return now }
+	assert.Empty(t, watcher.poll())
+
+	// Drops off and comes back within the debounce window
+	controller.set("site-a")
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, watcher.poll())
+	controller.set("site-a", phone)
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, watcher.poll())
+
+	// Roams and stays roamed
+	roamed := phone
+	roamed.APMac = MustParseMAC("aa:aa:aa:aa:aa:02")
+	controller.set("site-a", roamed)
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, watcher.poll())
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, watcher.poll())
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, []string{"site-a 11-11-11-11-11-11 roamed"}, eventSummaries(watcher.poll()))
+
+	// Joins briefly and leaves before the debounce elapses
+	visitor := testClient("44:44:44:44:44:44", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId2g, IP: "10.0.0.44"})
+	controller.set("site-a", roamed, visitor)
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, watcher.poll())
+	controller.set("site-a", roamed)
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, watcher.poll())
+	now = now.Add(2 * time.Minute)
+	assert.Empty(t, watcher.poll())
+}
+
+func TestWatcher_FailedPollsDoNotLookLikeClientsLeaving(t *testing.T) {
+	controller, server := newMockClientListController(t)
+	defer server.Close()
+	controller.set("site-a", testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g, IP: "10.0.0.11"}))
+
+	var errors []error
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	watcher := NewWatcher(c, WatcherConfig{OnError: func(err error) { errors = append(errors, err) }})
+	assert.Empty(t, watcher.poll())
+
+	controller.fail("site-a", true)
+	assert.Empty(t, watcher.poll())
+	assert.Len(t, errors, 1)
+	assert.EqualError(t, errors[0], "could not list clients for site site-a: unexpected response error: 500 500 Internal Server Error")
+
+	controller.fail("site-a", false)
+	assert.Empty(t, watcher.poll())
+}
+
+func TestWatcher_WatchesAllSitesAndEmitsInitialClientsWhenAsked(t *testing.T) {
+	controller, server := newMockClientListController(t)
+	defer server.Close()
+	controller.set("site-a", testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g, IP: "10.0.0.11"}))
+	controller.set("site-b", testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("bb:bb:bb:bb:bb:01"), RadioId: RadioId5g, IP: "10.1.0.22"}))
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	watcher := NewWatcher(c, WatcherConfig{EmitInitial: true, Interval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+
+	var events []ClientEvent
+	for i := 0; i < 2; i++ {
+		events = append(events, <-watcher.Events())
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	_, open := <-watcher.Events()
+	assert.False(t, open)
+
+	assert.ElementsMatch(t, []string{"site-a 11-11-11-11-11-11 joined", "site-b 22-22-22-22-22-22 joined"}, eventSummaries(events))
+
+	// The events channel has been closed, so a second run must not try to close it again
+	assert.ErrorIs(t, watcher.Run(context.Background()), ErrWatcherAlreadyRun)
+}
+
+func TestWatcher_ForgetsSitesRemovedFromTheController(t *testing.T) {
+	controller, server := newMockClientListController(t)
+	defer server.Close()
+	controller.set("site-a", testClient("11:11:11:11:11:11", ClientInfo{Wireless: true, APMac: MustParseMAC("aa:aa:aa:aa:aa:01"), RadioId: RadioId5g, IP: "10.0.0.11"}))
+	controller.set("site-b", testClient("22:22:22:22:22:22", ClientInfo{Wireless: true, APMac: MustParseMAC("bb:bb:bb:bb:bb:01"), RadioId: RadioId5g, IP: "10.1.0.22"}))
+
+	c := NewClient(server.URL, "my-cid", "my-client-id", "my-client-secret", true)
+	watcher := NewWatcher(c, WatcherConfig{})
+	assert.Empty(t, watcher.poll())
+
+	controller.remove("site-b")
+	assert.Equal(t, []string{"site-b 22-22-22-22-22-22 left"}, eventSummaries(watcher.poll()))
+	assert.Len(t, watcher.clients, 1)
+	assert.NotContains(t, watcher.initialised, "site-b")
+	assert.Empty(t, watcher.poll())
+}